@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock waits until the queue has space or the context is done.
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureDrop drops the email when the queue is full, reporting ErrQueueFull as its result.
+	BackpressureDrop BackpressurePolicy = "drop"
+	// BackpressureError returns ErrQueueFull when the queue is full.
+	BackpressureError BackpressurePolicy = "error"
+
+	DefaultAsyncQueueSize = 1000
+	DefaultAsyncWorkers   = 10
+)
+
+var (
+	ErrQueueFull                  = errors.New("email queue is full")
+	ErrAsyncEmailSenderIsShutdown = errors.New("async email sender is shut down")
+)
+
+// AsyncResult is the result of the email sent asynchronously.
+type AsyncResult struct {
+	EmailData object.EmailData
+	Result    *platform.SendResult
+	Err       error
+}
+
+type asyncJob struct {
+	ctx       context.Context
+	emailData object.EmailData
+	result    chan AsyncResult
+}
+
+// AsyncEmailSender queues the emails and sends them through the wrapped email sender by a pool of workers,
+// so the caller doesn't wait for the delivery. The caller context cancellation only applies to queueing,
+// since the caller context is usually done right after. The email is sent with the caller context values,
+// e.g. the access token required by ConfigServiceEmailSender.
+type AsyncEmailSender struct {
+	EmailSender EmailSender
+	Policy      BackpressurePolicy
+	// OnResult is called by the worker with the result of every email, optional.
+	OnResult func(result AsyncResult)
+
+	queue chan asyncJob
+	// done is closed on shutdown, releasing the enqueuers waiting for the queue space
+	done      chan struct{}
+	lock      sync.RWMutex
+	shutdown  bool
+	enqueuers sync.WaitGroup
+	workers   sync.WaitGroup
+}
+
+// NewAsyncEmailSender starts the workers sending the queued emails, onResult is optional.
+func NewAsyncEmailSender(emailSender EmailSender, queueSize, workers int, policy BackpressurePolicy,
+	onResult func(result AsyncResult)) *AsyncEmailSender {
+	if queueSize <= 0 {
+		queueSize = DefaultAsyncQueueSize
+	}
+	if workers <= 0 {
+		workers = DefaultAsyncWorkers
+	}
+	if policy == "" {
+		policy = BackpressureBlock
+	}
+	e := &AsyncEmailSender{
+		EmailSender: emailSender,
+		Policy:      policy,
+		OnResult:    onResult,
+		queue:       make(chan asyncJob, queueSize),
+		done:        make(chan struct{}),
+	}
+	e.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+// SendEmail queues the email and returns without waiting for the delivery.
+func (e *AsyncEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	return e.enqueue(ctx, asyncJob{ctx: ctx, emailData: emailData})
+}
+
+// SendEmailAsync queues the email and returns the channel receiving its result.
+func (e *AsyncEmailSender) SendEmailAsync(ctx context.Context, emailData object.EmailData) (<-chan AsyncResult, error) {
+	result := make(chan AsyncResult, 1)
+	if err := e.enqueue(ctx, asyncJob{ctx: ctx, emailData: emailData, result: result}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendEmailWithResult queues the email and waits for its result.
+func (e *AsyncEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	result, err := e.SendEmailAsync(ctx, emailData)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case asyncResult := <-result:
+		return asyncResult.Result, asyncResult.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendBatch sends the batch through the wrapped email sender without queueing, since the results are returned to the caller.
+func (e *AsyncEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	e.lock.RLock()
+	shutdown := e.shutdown
+	e.lock.RUnlock()
+	if shutdown {
+		return nil, ErrAsyncEmailSenderIsShutdown
+	}
+	return sendBatch(ctx, e.EmailSender, emailData, recipients)
+}
+
+func (e *AsyncEmailSender) CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error {
+	return cancelScheduledEmail(ctx, e.EmailSender, namespace, scheduleID)
+}
+
+// Shutdown stops accepting emails and waits until the queued emails are sent or the context is done.
+func (e *AsyncEmailSender) Shutdown(ctx context.Context) error {
+	e.lock.Lock()
+	if !e.shutdown {
+		e.shutdown = true
+		close(e.done)
+		// the queue is closed once the enqueuers in progress have returned, so none of them sends to the closed queue
+		go func() {
+			e.enqueuers.Wait()
+			close(e.queue)
+		}()
+	}
+	e.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QueueLength returns the emails waiting in the queue, e.g. for monitoring.
+func (e *AsyncEmailSender) QueueLength() int {
+	return len(e.queue)
+}
+
+func (e *AsyncEmailSender) enqueue(ctx context.Context, job asyncJob) error {
+	// the attachment readers could be closed or reused by the caller before the worker sends the email
+	if err := job.emailData.ReadAttachments(); err != nil {
+		return err
+	}
+
+	// the lock is only held to register the enqueuer, so Shutdown doesn't wait for the enqueuers blocked on the full queue
+	e.lock.RLock()
+	if e.shutdown {
+		e.lock.RUnlock()
+		return ErrAsyncEmailSenderIsShutdown
+	}
+	e.enqueuers.Add(1)
+	e.lock.RUnlock()
+	defer e.enqueuers.Done()
+
+	switch e.Policy {
+	case BackpressureDrop:
+		select {
+		case e.queue <- job:
+		default:
+			logrus.Warnf("email queue is full, email to %s is dropped", job.emailData.To)
+			e.report(job, nil, ErrQueueFull)
+		}
+		return nil
+	case BackpressureError:
+		select {
+		case e.queue <- job:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	default:
+		select {
+		case e.queue <- job:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.done:
+			return ErrAsyncEmailSenderIsShutdown
+		}
+	}
+}
+
+func (e *AsyncEmailSender) work() {
+	defer e.workers.Done()
+	for job := range e.queue {
+		result, err := SendEmailWithResult(detachedContext{parent: job.ctx}, e.EmailSender, job.emailData)
+		if err != nil {
+			logrus.Errorf("fail send email to %s asynchronously. error: %v", job.emailData.To, err)
+		}
+		e.report(job, result, err)
+	}
+}
+
+func (e *AsyncEmailSender) report(job asyncJob, result *platform.SendResult, err error) {
+	asyncResult := AsyncResult{EmailData: job.emailData, Result: result, Err: err}
+	if e.OnResult != nil {
+		e.OnResult(asyncResult)
+	}
+	if job.result != nil {
+		job.result <- asyncResult
+	}
+}
+
+// detachedContext keeps the values of the parent context without its cancellation and deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+// blockingEmailSender blocks every email until release is closed.
+type blockingEmailSender struct {
+	started chan object.EmailData
+	release chan struct{}
+
+	lock     sync.Mutex
+	sent     []object.EmailData
+	contexts []context.Context
+}
+
+func newBlockingEmailSender() *blockingEmailSender {
+	return &blockingEmailSender{
+		started: make(chan object.EmailData, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (s *blockingEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	s.started <- emailData
+	<-s.release
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sent = append(s.sent, emailData)
+	s.contexts = append(s.contexts, ctx)
+	return nil
+}
+
+func (s *blockingEmailSender) getSent() []object.EmailData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]object.EmailData(nil), s.sent...)
+}
+
+func (s *blockingEmailSender) waitStarted(t *testing.T) {
+	select {
+	case <-s.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the worker to start sending")
+	}
+}
+
+// newFullAsyncEmailSender returns the async email sender whose only worker is busy and queue is full.
+func newFullAsyncEmailSender(t *testing.T, policy BackpressurePolicy, onResult func(result AsyncResult)) (*AsyncEmailSender, *blockingEmailSender) {
+	emailSender := newBlockingEmailSender()
+	asyncEmailSender := NewAsyncEmailSender(emailSender, 1, 1, policy, onResult)
+	if err := asyncEmailSender.SendEmail(context.Background(), object.EmailData{To: "first@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	emailSender.waitStarted(t)
+	if err := asyncEmailSender.SendEmail(context.Background(), object.EmailData{To: "queued@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	return asyncEmailSender, emailSender
+}
+
+func TestAsyncEmailSender_Backpressure(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      BackpressurePolicy
+		expectedErr error
+		dropped     bool
+	}{
+		{name: "block until context is done", policy: BackpressureBlock, expectedErr: context.DeadlineExceeded},
+		{name: "drop", policy: BackpressureDrop, dropped: true},
+		{name: "error", policy: BackpressureError, expectedErr: ErrQueueFull},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var lock sync.Mutex
+			var results []AsyncResult
+			onResult := func(result AsyncResult) {
+				lock.Lock()
+				defer lock.Unlock()
+				results = append(results, result)
+			}
+			asyncEmailSender, emailSender := newFullAsyncEmailSender(t, tc.policy, onResult)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := asyncEmailSender.SendEmail(ctx, object.EmailData{To: "overflow@example.com"})
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+
+			close(emailSender.release)
+			if err = asyncEmailSender.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if sent := emailSender.getSent(); len(sent) != 2 {
+				t.Errorf("expected 2 emails sent, got %d", len(sent))
+			}
+			var droppedResults int
+			for _, result := range results {
+				if errors.Is(result.Err, ErrQueueFull) && result.EmailData.To == "overflow@example.com" {
+					droppedResults++
+				}
+			}
+			if tc.dropped != (droppedResults == 1) {
+				t.Errorf("expected dropped %v, got %d dropped results", tc.dropped, droppedResults)
+			}
+		})
+	}
+}
+
+func TestAsyncEmailSender_ShutdownReleasesBlockedEnqueuer(t *testing.T) {
+	asyncEmailSender, emailSender := newFullAsyncEmailSender(t, BackpressureBlock, nil)
+
+	enqueueErr := make(chan error, 1)
+	go func() {
+		enqueueErr <- asyncEmailSender.SendEmail(context.Background(), object.EmailData{To: "overflow@example.com"})
+	}()
+	// Shutdown doesn't wait for the blocked enqueuer
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	time.Sleep(10 * time.Millisecond)
+	if err := asyncEmailSender.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected Shutdown to wait for the worker until the deadline, got %v", err)
+	}
+	select {
+	case err := <-enqueueErr:
+		if err != ErrAsyncEmailSenderIsShutdown {
+			t.Errorf("expected ErrAsyncEmailSenderIsShutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the blocked enqueuer released by Shutdown")
+	}
+
+	close(emailSender.release)
+	if err := asyncEmailSender.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := emailSender.getSent(); len(sent) != 2 {
+		t.Errorf("expected the queued emails sent, got %d", len(sent))
+	}
+}
+
+func TestAsyncEmailSender_ShutdownDrainsQueue(t *testing.T) {
+	emailSender := newBlockingEmailSender()
+	close(emailSender.release)
+	asyncEmailSender := NewAsyncEmailSender(emailSender, 10, 2, BackpressureError, nil)
+	for i := 0; i < 10; i++ {
+		if err := asyncEmailSender.SendEmail(context.Background(), object.EmailData{To: "player@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := asyncEmailSender.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sent := emailSender.getSent(); len(sent) != 10 {
+		t.Errorf("expected all the queued emails sent, got %d", len(sent))
+	}
+	if err := asyncEmailSender.SendEmail(context.Background(), object.EmailData{To: "player@example.com"}); err != ErrAsyncEmailSenderIsShutdown {
+		t.Errorf("expected ErrAsyncEmailSenderIsShutdown, got %v", err)
+	}
+}
+
+type contextKey string
+
+func TestAsyncEmailSender_SendEmail(t *testing.T) {
+	emailSender := newBlockingEmailSender()
+	asyncEmailSender := NewAsyncEmailSender(emailSender, 1, 1, BackpressureBlock, nil)
+
+	attachment := bytes.NewBufferString("terms")
+	emailData := object.EmailData{
+		To:          "player@example.com",
+		Attachments: []object.Attachment{{Filename: "terms.txt", Reader: attachment}},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey("token"), "secret"))
+	if err := asyncEmailSender.SendEmail(ctx, emailData); err != nil {
+		t.Fatal(err)
+	}
+	// the caller returns, cancelling its context and reusing the attachment buffer
+	cancel()
+	attachment.Reset()
+	attachment.WriteString("reused")
+
+	close(emailSender.release)
+	if err := asyncEmailSender.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sent := emailSender.getSent()
+	if len(sent) != 1 {
+		t.Fatalf("expected sent once, got %d", len(sent))
+	}
+	if content := string(sent[0].Attachments[0].Content); content != "terms" {
+		t.Errorf("expected the attachment read before queueing, got %q", content)
+	}
+	if emailData.Attachments[0].Content != nil {
+		t.Error("expected the caller attachment kept as is")
+	}
+	sendCtx := emailSender.contexts[0]
+	if sendCtx.Err() != nil {
+		t.Errorf("expected the send context not cancelled, got %v", sendCtx.Err())
+	}
+	if value := sendCtx.Value(contextKey("token")); value != "secret" {
+		t.Errorf("expected the caller context value, got %v", value)
+	}
+}
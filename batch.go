@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/schedule"
+	"github.com/AccelByte/justice-go-common-email/template"
+	"github.com/sirupsen/logrus"
+)
+
+// BatchEmailSender is implemented by the email senders able to send an email to many recipients, each with its own merge vars.
+type BatchEmailSender interface {
+	EmailSender
+	/*
+		SendBatch sends the email to the recipients, packed into as few requests as the sender platform supports,
+		e.g. up to 1000 recipients per SendGrid request, and returns the result of every request.
+		The recipients of the email itself (To, ToList, CarbonCopy and Bcc) are ignored.
+		The error is only returned if the batch couldn't be sent at all, e.g. the configuration is not found.
+	*/
+	SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error)
+}
+
+// sendBatchOrSchedule sends the batch email through the sender platform, scheduling it locally
+// if it's scheduled later and the sender platform can't schedule it natively.
+func sendBatchOrSchedule(ctx context.Context, scheduler *schedule.Scheduler, senderPlatform platform.SenderPlatform,
+	platformID string, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	if !emailData.IsScheduled() || platform.CanSchedule(senderPlatform, emailData.SendAt) {
+		return platform.SendBatch(ctx, senderPlatform, platformID, emailData, recipients), nil
+	}
+	if scheduler == nil {
+		return nil, platform.ErrSchedulingNotSupported
+	}
+
+	if err := emailData.ReadAttachments(); err != nil {
+		return nil, err
+	}
+	sendAt := emailData.SendAt
+	emailData.SendAt = time.Time{}
+	result := platform.NewSendResult(platformID)
+	result.ScheduleID = scheduler.Schedule(sendAt, func() {
+		for _, batchResult := range platform.SendBatch(context.Background(), senderPlatform, platformID, emailData, recipients) {
+			if batchResult.Err != nil {
+				logrus.Errorf("fail send scheduled batch email to %d recipients. error: %v", len(batchResult.Recipients), batchResult.Err)
+			}
+		}
+	})
+	return []platform.BatchResult{{Recipients: object.GetRecipientAddresses(recipients), Result: result}}, nil
+}
+
+// sendRenderedBatch renders the email for each recipient with its own merge vars, and sends it to each recipient separately.
+func sendRenderedBatch(ctx context.Context, renderer template.Renderer, scheduler *schedule.Scheduler, senderPlatform platform.SenderPlatform,
+	platformID string, emailData object.EmailData, recipients []object.Recipient) []platform.BatchResult {
+	results := make([]platform.BatchResult, 0, len(recipients))
+	for _, recipient := range recipients {
+		recipientEmailData := emailData.ForRecipient(recipient)
+		batchResult := platform.BatchResult{Recipients: []string{recipient.Address.Address}}
+		batchResult.Err = renderTemplate(ctx, renderer, &recipientEmailData)
+		if batchResult.Err == nil {
+			batchResult.Result, batchResult.Err = sendOrSchedule(ctx, scheduler, senderPlatform, platformID, recipientEmailData)
+		}
+		results = append(results, batchResult)
+	}
+	return results
+}
+
+// sendBatch sends the batch email through the wrapped email sender, used by the email sender decorators.
+func sendBatch(ctx context.Context, emailSender EmailSender, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	if batchEmailSender, ok := emailSender.(BatchEmailSender); ok {
+		return batchEmailSender.SendBatch(ctx, emailData, recipients)
+	}
+	return nil, platform.ErrBatchNotSupported
+}
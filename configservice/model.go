@@ -16,6 +16,17 @@
 
 package configservice
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// DefaultPlatform is used when the configuration doesn't specify the platform.
+const DefaultPlatform = "sendgrid"
+
 type ErrorEntity struct {
 	ErrorCode    int    `json:"errorCode"`
 	ErrorMessage string `json:"errorMessage"`
@@ -26,6 +37,13 @@ type EmailTemplate struct {
 	TemplateID   string `json:"TemplateID"`
 }
 
+type RateLimit struct {
+	// RatePerSecond is the emails per second, zero means unlimited.
+	RatePerSecond float64 `json:"ratePerSecond"`
+	// Burst is the max emails sent at once. Default: RatePerSecond, at least 1.
+	Burst int `json:"burst,omitempty"`
+}
+
 type EmailSenderConfiguration struct {
 	Namespace             string           `json:"namespace"`
 	FromAddress           string           `json:"fromAddress"`
@@ -33,6 +51,57 @@ type EmailSenderConfiguration struct {
 	APIKey                string           `json:"apiKey"`
 	IsDomainAuthenticated bool             `json:"isDomainAuthenticated"`
 	EmailTemplates        []*EmailTemplate `json:"emailTemplates,omitempty"`
+	// Platform is the sender platform id, e.g. "sendgrid", "mandrill", "smtp", "ses". Default: sendgrid.
+	Platform string `json:"platform,omitempty"`
+	/*
+		PlatformSettings is the platform-specific configuration, using the same keys as the platform factory,
+		e.g. {"api_url": "...", "smtp_host": "..."} for mandrill or {"region": "...", "access_key_id": "..."} for ses.
+	*/
+	PlatformSettings map[string]string `json:"platformSettings,omitempty"`
+	// RateLimit limits the emails sent for the namespace, overriding the default namespace rate limit.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// PlatformRateLimit limits the emails sent using the platform settings (e.g. API key), shared by the namespaces using them.
+	PlatformRateLimit *RateLimit `json:"platformRateLimit,omitempty"`
+}
+
+func (d EmailSenderConfiguration) GetPlatform() string {
+	if d.Platform == "" {
+		return DefaultPlatform
+	}
+	return d.Platform
+}
+
+// GetPlatformConfig returns the platform configuration, APIKey is used as "api_key" unless it's set in PlatformSettings.
+func (d EmailSenderConfiguration) GetPlatformConfig() platform.Config {
+	config := platform.Config{}
+	if d.APIKey != "" {
+		config["api_key"] = d.APIKey
+	}
+	for key, value := range d.PlatformSettings {
+		config[key] = value
+	}
+	return config
+}
+
+// GetPlatformCacheKey returns the key identifying the sender platform built from this configuration,
+// so namespaces sharing the same platform and settings share the same sender platform instance.
+func (d EmailSenderConfiguration) GetPlatformCacheKey() string {
+	config := d.GetPlatformConfig()
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(d.GetPlatform()))
+	for _, key := range keys {
+		_, _ = hash.Write([]byte{0})
+		_, _ = hash.Write([]byte(key))
+		_, _ = hash.Write([]byte{0})
+		_, _ = hash.Write([]byte(config[key]))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
 func (d EmailSenderConfiguration) GetEmailTemplate(name string) *EmailTemplate {
@@ -27,7 +27,10 @@ import (
 	"github.com/AccelByte/justice-go-common-email/configservice"
 	"github.com/AccelByte/justice-go-common-email/object"
 	"github.com/AccelByte/justice-go-common-email/platform"
-	"github.com/AccelByte/justice-go-common-email/platform/sendgrid"
+	"github.com/AccelByte/justice-go-common-email/platform/circuitbreaker"
+	"github.com/AccelByte/justice-go-common-email/platform/ratelimit"
+	"github.com/AccelByte/justice-go-common-email/schedule"
+	"github.com/AccelByte/justice-go-common-email/template"
 	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 )
@@ -35,6 +38,20 @@ import (
 type ConfigServiceEmailSender struct {
 	ConfigServiceProxy  *configservice.APIProxy
 	SenderPlatformCache *cache.Cache
+	// CircuitBreakers holds the circuit breaker of each sender platform by platform cache key,
+	// so a failing API key of one namespace doesn't affect the others. It's nil if the circuit breaker is disabled.
+	CircuitBreakers *circuitbreaker.Group
+	// NamespaceRateLimiters limits the emails sent per namespace, its limit is overridden by the namespace configuration.
+	NamespaceRateLimiters *ratelimit.Group
+	// PlatformRateLimiters limits the emails sent per platform cache key, i.e. per platform settings (e.g. API key),
+	// its limit is overridden by the namespace configuration.
+	PlatformRateLimiters *ratelimit.Group
+	RateLimitMode        ratelimit.Mode
+	// Scheduler schedules the emails locally if the sender platform can't schedule them natively.
+	Scheduler *schedule.Scheduler
+	// TemplateRenderer renders XMCTemplate locally when set, instead of relying on the template stored by the provider.
+	// It's required to send templates through the platforms without provider templates, e.g. smtp.
+	TemplateRenderer template.Renderer
 }
 
 func NewConfigServiceEmailSender() (*ConfigServiceEmailSender, error) {
@@ -67,29 +84,101 @@ func NewConfigServiceEmailSender() (*ConfigServiceEmailSender, error) {
 	}
 	senderPlatformCache := cache.New(time.Duration(senderCacheExpire)*time.Second, time.Duration(senderCacheExpire)*2*time.Second)
 
-	return &ConfigServiceEmailSender{
+	emailSender := &ConfigServiceEmailSender{
 		ConfigServiceProxy:  configServiceProxy,
 		SenderPlatformCache: senderPlatformCache,
-	}, nil
+		Scheduler:           schedule.NewScheduler(),
+	}
+
+	if emailSender.TemplateRenderer, err = getTemplateRendererFromEnv(); err != nil {
+		return nil, err
+	}
+
+	circuitBreakerSettings, err := getCircuitBreakerSettingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if circuitBreakerSettings != nil {
+		emailSender.CircuitBreakers = circuitbreaker.NewGroup(*circuitBreakerSettings)
+	}
+
+	if emailSender.RateLimitMode, err = ratelimit.ParseMode(os.Getenv("APP_EMAIL_RATE_LIMIT_MODE")); err != nil {
+		return nil, err
+	}
+	namespaceRateLimit, err := getRateLimitFromEnv("APP_EMAIL_NAMESPACE_RATE_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	emailSender.NamespaceRateLimiters = ratelimit.NewGroup(namespaceRateLimit)
+	platformRateLimit, err := getRateLimitFromEnv("APP_EMAIL_PLATFORM_RATE_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	emailSender.PlatformRateLimiters = ratelimit.NewGroup(platformRateLimit)
+
+	return emailSender, nil
+}
+
+// GetCircuitBreakerStates returns the circuit state of each sender platform by platform cache key, e.g. for health checks.
+// The key is the hash of the platform settings, see configservice.EmailSenderConfiguration.GetPlatformCacheKey.
+// It returns nil if the circuit breaker is disabled.
+func (e *ConfigServiceEmailSender) GetCircuitBreakerStates() map[string]circuitbreaker.State {
+	if e.CircuitBreakers == nil {
+		return nil
+	}
+	return e.CircuitBreakers.States()
 }
 
 func (e *ConfigServiceEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+func (e *ConfigServiceEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	senderPlatform, platformID, err := e.prepareEmail(ctx, &emailData)
+	if err != nil {
+		return nil, err
+	}
+	if e.TemplateRenderer != nil && emailData.XMCTemplate != "" {
+		if err = renderTemplate(ctx, e.TemplateRenderer, &emailData); err != nil {
+			return nil, err
+		}
+	}
+	return sendOrSchedule(ctx, e.Scheduler, senderPlatform, platformID, emailData)
+}
+
+// SendBatch sends the email to the recipients, each with its own merge vars, in as few requests as the sender platform supports.
+// The namespace rate limit is taken once for the whole batch.
+// If TemplateRenderer is set, the email is rendered and sent to each recipient separately.
+func (e *ConfigServiceEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	senderPlatform, platformID, err := e.prepareEmail(ctx, &emailData)
+	if err != nil {
+		return nil, err
+	}
+	if e.TemplateRenderer == nil || emailData.XMCTemplate == "" {
+		return sendBatchOrSchedule(ctx, e.Scheduler, senderPlatform, platformID, emailData, recipients)
+	}
+	return sendRenderedBatch(ctx, e.TemplateRenderer, e.Scheduler, senderPlatform, platformID, emailData, recipients), nil
+}
+
+// prepareEmail fills the email from the namespace configuration and returns the sender platform to send it.
+func (e *ConfigServiceEmailSender) prepareEmail(ctx context.Context, emailData *object.EmailData) (platform.SenderPlatform, string, error) {
 	if emailData.Namespace == "" {
-		return errors.New("namespace is not specified yet")
+		return nil, "", errors.New("namespace is not specified yet")
 	}
 
 	emailSenderConfiguration, err := e.ConfigServiceProxy.GetEmailSenderConfiguration(ctx, emailData.Namespace)
 	if err != nil {
 		logrus.Errorf("fail get email sender configuration. error: %v", err)
-		return err
+		return nil, "", err
 	}
 	if emailSenderConfiguration == nil {
 		logrus.Errorf("email sender configuration for namespace %s is not found", emailData.Namespace)
-		return ErrConfigurationNotFound
+		return nil, "", ErrConfigurationNotFound
 	}
 	if !emailSenderConfiguration.IsDomainAuthenticated {
 		logrus.Errorf("email sender domain for namespace %s is not authenticated yet", emailData.Namespace)
-		return ErrConfigurationNotValid
+		return nil, "", ErrConfigurationNotValid
 	}
 
 	if emailData.From == "" {
@@ -98,7 +187,7 @@ func (e *ConfigServiceEmailSender) SendEmail(ctx context.Context, emailData obje
 	if emailData.FromName == "" {
 		emailData.FromName = emailSenderConfiguration.FromName
 	}
-	if emailData.XMCTemplate != "" {
+	if emailData.XMCTemplate != "" && e.TemplateRenderer == nil {
 		// if XMCTemplate is specified, we will try to replace it with email template from Sender Configuration,
 		// but if email template not found, we will keep use the specified value.
 
@@ -109,22 +198,83 @@ func (e *ConfigServiceEmailSender) SendEmail(ctx context.Context, emailData obje
 	}
 	emailData.SetTemplateAdditionalData()
 
-	senderPlatform := e.getSenderPlatform(emailSenderConfiguration.APIKey)
+	senderPlatform := e.getSenderPlatform(emailSenderConfiguration)
 	if senderPlatform == nil {
 		logrus.Errorf("sender platform for namespace %s is not exist", emailData.Namespace)
+		return nil, "", ErrSenderPlatformNotExist
+	}
+	if emailData.XMCTemplate != "" && e.TemplateRenderer == nil && !platform.CanSendTemplate(senderPlatform) {
+		// the platform would send the email without content
+		logrus.Errorf("%s sender platform for namespace %s can't send template %s without template renderer",
+			emailSenderConfiguration.GetPlatform(), emailData.Namespace, emailData.XMCTemplate)
+		return nil, "", platform.ErrTemplateNotSupported
+	}
+
+	if e.NamespaceRateLimiters != nil {
+		limit := e.NamespaceRateLimiters.Limit
+		if emailSenderConfiguration.RateLimit != nil {
+			limit = toRateLimit(emailSenderConfiguration.RateLimit)
+		}
+		if !limit.IsUnlimited() {
+			limiter := e.NamespaceRateLimiters.GetWithLimit(emailData.Namespace, limit)
+			if err = takeNamespaceRateLimit(ctx, limiter, e.RateLimitMode, emailData.Namespace); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if e.PlatformRateLimiters != nil {
+		limit := e.PlatformRateLimiters.Limit
+		if emailSenderConfiguration.PlatformRateLimit != nil {
+			limit = toRateLimit(emailSenderConfiguration.PlatformRateLimit)
+		}
+		if !limit.IsUnlimited() {
+			limiter := e.PlatformRateLimiters.GetWithLimit(emailSenderConfiguration.GetPlatformCacheKey(), limit)
+			senderPlatform = ratelimit.NewRateLimitSenderPlatform(emailSenderConfiguration.GetPlatform(), senderPlatform, limiter, e.RateLimitMode)
+		}
+	}
+	return senderPlatform, emailSenderConfiguration.GetPlatform(), nil
+}
+
+// CancelScheduledEmail cancels the email of the namespace scheduled with SendAt by SendResult.ScheduleID.
+func (e *ConfigServiceEmailSender) CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error {
+	if e.Scheduler != nil && e.Scheduler.Cancel(scheduleID) {
+		return nil
+	}
+
+	emailSenderConfiguration, err := e.ConfigServiceProxy.GetEmailSenderConfiguration(ctx, namespace)
+	if err != nil {
+		logrus.Errorf("fail get email sender configuration. error: %v", err)
+		return err
+	}
+	if emailSenderConfiguration == nil {
+		logrus.Errorf("email sender configuration for namespace %s is not found", namespace)
+		return ErrConfigurationNotFound
+	}
+	senderPlatform := e.getSenderPlatform(emailSenderConfiguration)
+	if senderPlatform == nil {
+		logrus.Errorf("sender platform for namespace %s is not exist", namespace)
 		return ErrSenderPlatformNotExist
 	}
-	return senderPlatform.Send(ctx, emailData)
+	return platform.CancelScheduled(ctx, senderPlatform, scheduleID)
 }
 
-func (e *ConfigServiceEmailSender) getSenderPlatform(apiKey string) (senderPlatform platform.SenderPlatform) {
-	result, found := e.SenderPlatformCache.Get(apiKey)
+func (e *ConfigServiceEmailSender) getSenderPlatform(config *configservice.EmailSenderConfiguration) (senderPlatform platform.SenderPlatform) {
+	cacheKey := config.GetPlatformCacheKey()
+	result, found := e.SenderPlatformCache.Get(cacheKey)
 	if found {
 		senderPlatform = result.(platform.SenderPlatform)
 	} else {
-		// We only supports SendGrid for now
-		senderPlatform = sendgrid.NewSendGridClient(apiKey, "")
-		e.SenderPlatformCache.Set(apiKey, senderPlatform, 0)
+		var err error
+		senderPlatform, err = platform.New(config.GetPlatform(), config.GetPlatformConfig())
+		if err != nil {
+			logrus.Errorf("fail initialize %s sender platform for namespace %s. error: %v", config.GetPlatform(), config.Namespace, err)
+			return nil
+		}
+		if e.CircuitBreakers != nil {
+			// the breaker outlives the cached sender platform, so its state is kept when the cache expires
+			senderPlatform = circuitbreaker.NewCircuitBreakerSenderPlatform(config.GetPlatform(), senderPlatform, e.CircuitBreakers.Get(cacheKey))
+		}
+		e.SenderPlatformCache.Set(cacheKey, senderPlatform, 0)
 	}
 	return senderPlatform
 }
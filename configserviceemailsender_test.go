@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/configservice"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/template"
+	"github.com/patrickmn/go-cache"
+)
+
+// startSMTPServer starts a plaintext SMTP server accepting any email, the received messages are sent to the channel.
+func startSMTPServer(t *testing.T) (int, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+			_ = text.PrintfLine("250 OK")
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, err := bufio.NewReader(text.DotReader()).ReadString(0)
+			if err != nil && data == "" {
+				return
+			}
+			messages <- data
+			_ = text.PrintfLine("250 OK")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func newTestConfigServiceEmailSender(config *configservice.EmailSenderConfiguration) *ConfigServiceEmailSender {
+	configServiceProxy := &configservice.APIProxy{Cache: cache.New(time.Minute, time.Minute)}
+	configServiceProxy.Cache.Set(config.Namespace, config, 0)
+	return &ConfigServiceEmailSender{
+		ConfigServiceProxy:  configServiceProxy,
+		SenderPlatformCache: cache.New(time.Minute, time.Minute),
+	}
+}
+
+func TestConfigServiceEmailSender_SMTPTemplate(t *testing.T) {
+	port, messages := startSMTPServer(t)
+	config := &configservice.EmailSenderConfiguration{
+		Namespace:             "mygame",
+		FromAddress:           "noreply@mygame.com",
+		IsDomainAuthenticated: true,
+		Platform:              "smtp",
+		PlatformSettings: map[string]string{
+			"host":     "127.0.0.1",
+			"port":     strconv.Itoa(port),
+			"tls_mode": "none",
+		},
+	}
+	renderer, err := template.NewFSRenderer(fstest.MapFS{
+		"verify.subject.tmpl": {Data: []byte("Verify {{.name}}")},
+		"verify.txt.tmpl":     {Data: []byte("Your code is {{.code}}")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emailData := object.EmailData{
+		Namespace:    "mygame",
+		To:           "player@example.com",
+		XMCTemplate:  "verify",
+		XMCMergeVars: map[string]interface{}{"name": "Player", "code": "123456"},
+	}
+
+	t.Run("without renderer", func(t *testing.T) {
+		emailSender := newTestConfigServiceEmailSender(config)
+		if _, err := emailSender.SendEmailWithResult(context.Background(), emailData); !errors.Is(err, platform.ErrTemplateNotSupported) {
+			t.Fatalf("expected ErrTemplateNotSupported, got %v", err)
+		}
+		select {
+		case message := <-messages:
+			t.Fatalf("expected no email sent, got %q", message)
+		default:
+		}
+	})
+
+	t.Run("with renderer", func(t *testing.T) {
+		emailSender := newTestConfigServiceEmailSender(config)
+		emailSender.TemplateRenderer = renderer
+		if _, err := emailSender.SendEmailWithResult(context.Background(), emailData); err != nil {
+			t.Fatalf("expected sent, got %v", err)
+		}
+		select {
+		case message := <-messages:
+			if !strings.Contains(message, "Subject: Verify Player") {
+				t.Errorf("expected rendered subject, got %q", message)
+			}
+			if !strings.Contains(message, "Your code is 123456") {
+				t.Errorf("expected rendered body, got %q", message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected email sent")
+		}
+	})
+
+	t.Run("without template", func(t *testing.T) {
+		emailSender := newTestConfigServiceEmailSender(config)
+		contentEmailData := emailData
+		contentEmailData.XMCTemplate = ""
+		contentEmailData.Subject = "Hello"
+		contentEmailData.TextBody = "Hello Player"
+		if _, err := emailSender.SendEmailWithResult(context.Background(), contentEmailData); err != nil {
+			t.Fatalf("expected sent, got %v", err)
+		}
+		select {
+		case message := <-messages:
+			if !strings.Contains(message, "Hello Player") {
+				t.Errorf("expected body, got %q", message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected email sent")
+		}
+	})
+}
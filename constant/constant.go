@@ -24,6 +24,10 @@ const (
 	CopyrightYearTemplateKey = "CopyrightYear"
 	LanguageTagTemplateKey   = "LanguageTag"
 
+	// NamespaceMetadataKey is the key of the namespace in the custom metadata of the sent email,
+	// it's reported back by the provider webhooks along with the delivery events.
+	NamespaceMetadataKey = "namespace"
+
 	DefaultHTTPTimeoutInSeconds = 10
 
 	ServiceAccessToken ContextKey = "ServiceAccessToken"
@@ -22,6 +22,15 @@ import (
 	"fmt"
 
 	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+
+	// register the built-in sender platforms
+	_ "github.com/AccelByte/justice-go-common-email/platform/mailgun"
+	_ "github.com/AccelByte/justice-go-common-email/platform/mandrill"
+	_ "github.com/AccelByte/justice-go-common-email/platform/postmark"
+	_ "github.com/AccelByte/justice-go-common-email/platform/sendgrid"
+	_ "github.com/AccelByte/justice-go-common-email/platform/ses"
+	_ "github.com/AccelByte/justice-go-common-email/platform/smtp"
 )
 
 type EmailConfigSource string
@@ -41,6 +50,13 @@ type EmailSender interface {
 	SendEmail(ctx context.Context, emailData object.EmailData) error
 }
 
+// ResultEmailSender is implemented by the email senders able to return the provider result, e.g. the provider message id.
+// All the built-in email senders implement it.
+type ResultEmailSender interface {
+	EmailSender
+	SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error)
+}
+
 func NewEmailSender(configSource EmailConfigSource) (EmailSender, error) {
 	switch configSource {
 	case StaticSource:
@@ -51,3 +67,16 @@ func NewEmailSender(configSource EmailConfigSource) (EmailSender, error) {
 		return nil, fmt.Errorf("unsupported %s config source", configSource)
 	}
 }
+
+// SendEmailWithResult sends the email through the email sender, and returns the provider result if it's supported.
+// For the email senders that only implement EmailSender, the result doesn't have the provider and the provider message id.
+func SendEmailWithResult(ctx context.Context, emailSender EmailSender, emailData object.EmailData) (*platform.SendResult, error) {
+	if resultEmailSender, ok := emailSender.(ResultEmailSender); ok {
+		return resultEmailSender.SendEmailWithResult(ctx, emailData)
+	}
+	result := platform.NewSendResult("")
+	if err := emailSender.SendEmail(ctx, emailData); err != nil {
+		return nil, err
+	}
+	return result.Complete(emailData), nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform/ratelimit"
+)
+
+// mockEmailSender only implements EmailSender, like the mocks of the services using this library.
+type mockEmailSender struct {
+	sent []object.EmailData
+	err  error
+}
+
+func (m *mockEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	m.sent = append(m.sent, emailData)
+	return m.err
+}
+
+func TestSendEmailWithResult(t *testing.T) {
+	emailData := object.EmailData{Namespace: "mygame", To: "player@example.com"}
+
+	t.Run("email sender without result", func(t *testing.T) {
+		emailSender := &mockEmailSender{}
+		result, err := SendEmailWithResult(context.Background(), emailSender, emailData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.AcceptedRecipients) != 1 || result.AcceptedRecipients[0] != "player@example.com" {
+			t.Errorf("expected the recipient accepted, got %v", result.AcceptedRecipients)
+		}
+		if len(emailSender.sent) != 1 {
+			t.Errorf("expected sent once, got %d", len(emailSender.sent))
+		}
+	})
+
+	t.Run("email sender error", func(t *testing.T) {
+		sendErr := errors.New("send failed")
+		if _, err := SendEmailWithResult(context.Background(), &mockEmailSender{err: sendErr}, emailData); err != sendErr {
+			t.Errorf("expected the send error, got %v", err)
+		}
+	})
+
+	t.Run("decorated email sender without result", func(t *testing.T) {
+		emailSender := &mockEmailSender{}
+		var rateLimitEmailSender ResultEmailSender = NewRateLimitEmailSender(emailSender, ratelimit.Limit{}, ratelimit.ModeBlock)
+		result, err := rateLimitEmailSender.SendEmailWithResult(context.Background(), emailData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.AcceptedRecipients) != 1 || len(emailSender.sent) != 1 {
+			t.Errorf("expected sent through the decorator, got %v", result)
+		}
+	})
+}
+
+func TestBuiltInEmailSendersReturnResult(t *testing.T) {
+	emailSenders := []EmailSender{
+		&StaticEmailSender{},
+		&ConfigServiceEmailSender{},
+		&AsyncEmailSender{},
+		&IdempotentEmailSender{},
+		&RateLimitEmailSender{},
+		&SuppressionEmailSender{},
+	}
+	for _, emailSender := range emailSenders {
+		if _, ok := emailSender.(ResultEmailSender); !ok {
+			t.Errorf("expected %T to implement ResultEmailSender", emailSender)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// getIntEnv returns the environment variable as integer, or defaultValue if it's not set.
+func getIntEnv(key string, defaultValue int) (int, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s value must be an integer", key)
+	}
+	return value, nil
+}
+
+// getFloatEnv returns the environment variable as float, or defaultValue if it's not set.
+func getFloatEnv(key string, defaultValue float64) (float64, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s value must be a number", key)
+	}
+	return value, nil
+}
+
+// getMillisecondsEnv returns the environment variable in milliseconds as duration, or defaultValue if it's not set.
+func getMillisecondsEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, err := getIntEnv(key, int(defaultValue/time.Millisecond))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(value) * time.Millisecond, nil
+}
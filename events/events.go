@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Type is the normalized type of the delivery event.
+type Type string
+
+const (
+	TypeDelivered    Type = "delivered"
+	TypeBouncedHard  Type = "bounced-hard"
+	TypeBouncedSoft  Type = "bounced-soft"
+	TypeDeferred     Type = "deferred"
+	TypeOpened       Type = "opened"
+	TypeClicked      Type = "clicked"
+	TypeComplained   Type = "complained"
+	TypeUnsubscribed Type = "unsubscribed"
+)
+
+var types = []Type{
+	TypeDelivered,
+	TypeBouncedHard,
+	TypeBouncedSoft,
+	TypeDeferred,
+	TypeOpened,
+	TypeClicked,
+	TypeComplained,
+	TypeUnsubscribed,
+}
+
+// ParseType parses the delivery event type, returns false if the type is unknown.
+func ParseType(str string) (Type, bool) {
+	for _, t := range types {
+		if string(t) == str {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// IsBounce returns true if the email is not delivered to the recipient, either permanently or temporarily.
+func (t Type) IsBounce() bool {
+	return t == TypeBouncedHard || t == TypeBouncedSoft
+}
+
+// IsPermanentFailure returns true if the recipient shouldn't receive any more emails,
+// i.e. the address doesn't exist, or the recipient complained or unsubscribed.
+func (t Type) IsPermanentFailure() bool {
+	return t == TypeBouncedHard || t == TypeComplained || t == TypeUnsubscribed
+}
+
+// DeliveryEvent is the delivery event reported by the provider webhook, normalized across the providers.
+type DeliveryEvent struct {
+	Type Type
+	// Provider is the sender platform id, e.g. "sendgrid".
+	Provider string
+	// Namespace is the namespace that sent the email, empty if the provider doesn't report it.
+	Namespace string
+	Recipient string
+	// MessageID matches SendResult.MessageID of the sent email.
+	MessageID string
+	Timestamp time.Time
+	// Reason is the provider description of the bounce or deferral, if any.
+	Reason string
+	// URL is the clicked link of the click event.
+	URL string
+	// Raw is the event payload as reported by the provider.
+	Raw json.RawMessage
+}
+
+// Handler handles the delivery events. Returning an error makes the webhook respond with failure,
+// so the provider retries the delivery of the events later.
+type Handler interface {
+	HandleDeliveryEvent(ctx context.Context, event DeliveryEvent) error
+}
+
+type HandlerFunc func(ctx context.Context, event DeliveryEvent) error
+
+func (f HandlerFunc) HandleDeliveryEvent(ctx context.Context, event DeliveryEvent) error {
+	return f(ctx, event)
+}
+
+// Handlers passes the delivery event to each of the handlers in order, stopping at the first error.
+type Handlers []Handler
+
+func (h Handlers) HandleDeliveryEvent(ctx context.Context, event DeliveryEvent) error {
+	for _, handler := range h {
+		if err := handler.HandleDeliveryEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
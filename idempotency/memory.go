@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/patrickmn/go-cache"
+)
+
+// MemoryStore records the idempotency keys in memory, so it only deduplicates the emails sent by the same instance.
+type MemoryStore struct {
+	Cache *cache.Cache
+}
+
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	return &MemoryStore{
+		Cache: cache.New(DefaultWindow, cleanupInterval),
+	}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key string, ttl time.Duration) (*Record, error) {
+	for {
+		if err := s.Cache.Add(key, Record{}, ttl); err == nil {
+			return nil, nil
+		}
+		// the key could expire right after Add fails, then try to reserve it again
+		if value, found := s.Cache.Get(key); found {
+			record := value.(Record)
+			return &record, nil
+		}
+	}
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, result *platform.SendResult, ttl time.Duration) error {
+	s.Cache.Set(key, Record{Completed: true, Result: result}, ttl)
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.Cache.Delete(key)
+	return nil
+}
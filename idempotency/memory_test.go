@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Minute)
+
+	record, err := store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("expected the key reserved, got %v, %v", record, err)
+	}
+	record, err = store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record == nil || record.Completed {
+		t.Fatalf("expected the key in progress, got %v, %v", record, err)
+	}
+
+	if err = store.Complete(ctx, "mygame:verify-1", &platform.SendResult{MessageID: "abc123"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	record, err = store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record == nil || !record.Completed || record.Result.MessageID != "abc123" {
+		t.Fatalf("expected the completed record, got %v, %v", record, err)
+	}
+
+	if err = store.Release(ctx, "mygame:verify-1"); err != nil {
+		t.Fatal(err)
+	}
+	if record, err = store.Reserve(ctx, "mygame:verify-1", time.Minute); err != nil || record != nil {
+		t.Fatalf("expected the released key reserved again, got %v, %v", record, err)
+	}
+}
+
+func TestMemoryStore_LeaseExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(time.Minute)
+
+	if _, err := store.Reserve(ctx, "mygame:verify-1", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// the instance reserving the key crashed, the key is reserved again after the lease
+	if record, err := store.Reserve(ctx, "mygame:verify-1", time.Minute); err != nil || record != nil {
+		t.Fatalf("expected the expired key reserved again, got %v, %v", record, err)
+	}
+}
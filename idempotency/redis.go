@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// RedisClient is the subset of Redis commands used by RedisStore, to be adapted from any Redis client.
+type RedisClient interface {
+	// SetNX sets the key only if it doesn't exist (SET key value NX PX ttl) and returns whether it's set.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Get returns found false if the key doesn't exist.
+	Get(ctx context.Context, key string) (value string, found bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStore records the idempotency keys in Redis, so the emails are deduplicated across the instances.
+type RedisStore struct {
+	Client    RedisClient
+	KeyPrefix string
+}
+
+func NewRedisStore(client RedisClient, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		Client:    client,
+		KeyPrefix: keyPrefix,
+	}
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, key string, ttl time.Duration) (*Record, error) {
+	value, err := json.Marshal(Record{})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		reserved, err := s.Client.SetNX(ctx, s.KeyPrefix+key, string(value), ttl)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+		// the key could expire right after SetNX fails, then try to reserve it again
+		existing, found, err := s.Client.Get(ctx, s.KeyPrefix+key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			record := &Record{}
+			if err = json.Unmarshal([]byte(existing), record); err != nil {
+				return nil, err
+			}
+			return record, nil
+		}
+	}
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, result *platform.SendResult, ttl time.Duration) error {
+	value, err := json.Marshal(Record{Completed: true, Result: result})
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(ctx, s.KeyPrefix+key, string(value), ttl)
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.Client.Del(ctx, s.KeyPrefix+key)
+}
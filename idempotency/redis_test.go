@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// fakeRedisClient keeps the values in a map, failing the calls with err if set.
+type fakeRedisClient struct {
+	values map[string]string
+	// expireOnGet removes the key on Get once, like the key expiring right after SetNX fails.
+	expireOnGet bool
+	err         error
+}
+
+func (c *fakeRedisClient) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	if _, exists := c.values[key]; exists {
+		return false, nil
+	}
+	c.values[key] = value
+	return true, nil
+}
+
+func (c *fakeRedisClient) Get(ctx context.Context, key string) (string, bool, error) {
+	if c.expireOnGet {
+		c.expireOnGet = false
+		delete(c.values, key)
+	}
+	value, found := c.values[key]
+	return value, found, c.err
+}
+
+func (c *fakeRedisClient) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.values[key] = value
+	return c.err
+}
+
+func (c *fakeRedisClient) Del(ctx context.Context, key string) error {
+	delete(c.values, key)
+	return c.err
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeRedisClient{values: map[string]string{}}
+	store := NewRedisStore(client, "email:idempotency:")
+
+	record, err := store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("expected the key reserved, got %v, %v", record, err)
+	}
+	if _, exists := client.values["email:idempotency:mygame:verify-1"]; !exists {
+		t.Errorf("expected the key prefixed, got %v", client.values)
+	}
+	record, err = store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record == nil || record.Completed {
+		t.Fatalf("expected the key in progress, got %v, %v", record, err)
+	}
+
+	if err = store.Complete(ctx, "mygame:verify-1", &platform.SendResult{MessageID: "abc123"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	record, err = store.Reserve(ctx, "mygame:verify-1", time.Minute)
+	if err != nil || record == nil || !record.Completed || record.Result.MessageID != "abc123" {
+		t.Fatalf("expected the completed record, got %v, %v", record, err)
+	}
+
+	if err = store.Release(ctx, "mygame:verify-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.values) != 0 {
+		t.Errorf("expected the key deleted, got %v", client.values)
+	}
+}
+
+func TestRedisStore_Reserve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("key expired after SetNX failed", func(t *testing.T) {
+		client := &fakeRedisClient{values: map[string]string{"mygame:verify-1": `{"Completed":false}`}, expireOnGet: true}
+		record, err := NewRedisStore(client, "").Reserve(ctx, "mygame:verify-1", time.Minute)
+		if err != nil || record != nil {
+			t.Fatalf("expected the key reserved on the next attempt, got %v, %v", record, err)
+		}
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		clientErr := errors.New("connection refused")
+		client := &fakeRedisClient{values: map[string]string{}, err: clientErr}
+		if _, err := NewRedisStore(client, "").Reserve(ctx, "mygame:verify-1", time.Minute); err != clientErr {
+			t.Errorf("expected the client error, got %v", err)
+		}
+	})
+
+	t.Run("corrupted record", func(t *testing.T) {
+		client := &fakeRedisClient{values: map[string]string{"mygame:verify-1": "not json"}}
+		if _, err := NewRedisStore(client, "").Reserve(ctx, "mygame:verify-1", time.Minute); err == nil {
+			t.Error("expected error of the corrupted record")
+		}
+	})
+}
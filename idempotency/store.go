@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+const (
+	// DefaultWindow is how long the idempotency key is remembered by default.
+	DefaultWindow = 24 * time.Hour
+	// DefaultLease is how long the key is reserved while the email is being sent by default, covering the send retries.
+	// The reservation expires after the lease if the instance crashes, so the email could be sent again.
+	DefaultLease = time.Minute
+)
+
+var ErrInProgress = errors.New("email with the same idempotency key is being sent")
+
+// Record is the state of the idempotency key.
+type Record struct {
+	// Completed is false while the email is being sent.
+	Completed bool
+	Result    *platform.SendResult
+}
+
+// Store records the idempotency keys.
+type Store interface {
+	// Reserve reserves the key for the ttl (the lease) and returns nil if it's not recorded yet, otherwise returns the existing record.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (*Record, error)
+	// Complete records the result of the email sent with the reserved key.
+	Complete(ctx context.Context, key string, result *platform.SendResult, ttl time.Duration) error
+	// Release removes the reserved key, e.g. if the email failed, so it could be sent again.
+	Release(ctx context.Context, key string) error
+}
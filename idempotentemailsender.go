@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/idempotency"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotentEmailSender sends the email with the same IdempotencyKey and namespace only once within the window,
+// returning the original result for the duplicates. The emails without IdempotencyKey are always sent.
+type IdempotentEmailSender struct {
+	EmailSender EmailSender
+	Store       idempotency.Store
+	Window      time.Duration
+	// Lease is how long the key is reserved while the email is being sent, it should exceed the longest send.
+	Lease time.Duration
+}
+
+func NewIdempotentEmailSender(emailSender EmailSender, store idempotency.Store, window time.Duration) *IdempotentEmailSender {
+	if window <= 0 {
+		window = idempotency.DefaultWindow
+	}
+	return &IdempotentEmailSender{
+		EmailSender: emailSender,
+		Store:       store,
+		Window:      window,
+		Lease:       idempotency.DefaultLease,
+	}
+}
+
+func (e *IdempotentEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+// SendEmailWithResult returns idempotency.ErrInProgress if the email with the same key is still being sent.
+func (e *IdempotentEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if emailData.IdempotencyKey == "" {
+		return SendEmailWithResult(ctx, e.EmailSender, emailData)
+	}
+
+	key := emailData.Namespace + ":" + emailData.IdempotencyKey
+	record, err := e.Store.Reserve(ctx, key, e.Lease)
+	if err != nil {
+		logrus.Errorf("fail reserve email idempotency key %s. error: %v", key, err)
+		return nil, err
+	}
+	if record != nil {
+		if !record.Completed {
+			return nil, idempotency.ErrInProgress
+		}
+		logrus.Infof("email with idempotency key %s is already sent", key)
+		return record.Result, nil
+	}
+
+	result, err := SendEmailWithResult(ctx, e.EmailSender, emailData)
+	// the email partially sent, e.g. some recipients are rejected, is completed so the retry doesn't send it again
+	if err != nil && (result == nil || len(result.AcceptedRecipients) == 0) {
+		// the failed email could be sent again by the caller retry
+		if errRelease := e.Store.Release(ctx, key); errRelease != nil {
+			logrus.Errorf("fail release email idempotency key %s. error: %v", key, errRelease)
+		}
+		return result, err
+	}
+	if errComplete := e.Store.Complete(ctx, key, result, e.Window); errComplete != nil {
+		logrus.Errorf("fail record email idempotency key %s. error: %v", key, errComplete)
+	}
+	return result, err
+}
+
+// SendBatch sends the batch through the wrapped email sender without deduplication, the batch results aren't recorded.
+func (e *IdempotentEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	return sendBatch(ctx, e.EmailSender, emailData, recipients)
+}
+
+func (e *IdempotentEmailSender) CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error {
+	return cancelScheduledEmail(ctx, e.EmailSender, namespace, scheduleID)
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/idempotency"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// resultEmailSender returns the results and errors in order, then succeeds.
+type resultEmailSender struct {
+	results []*platform.SendResult
+	errs    []error
+	calls   int
+}
+
+func (m *resultEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := m.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+func (m *resultEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	m.calls++
+	var result *platform.SendResult
+	var err error
+	if m.calls <= len(m.results) {
+		result = m.results[m.calls-1]
+	}
+	if m.calls <= len(m.errs) {
+		err = m.errs[m.calls-1]
+	}
+	if result == nil && err == nil {
+		result = platform.NewSendResult("mock").Complete(emailData)
+	}
+	return result, err
+}
+
+func TestIdempotentEmailSender_SendEmailWithResult(t *testing.T) {
+	sendErr := &platform.Error{Provider: "mock", Kind: platform.ErrProviderUnavailable, Retryable: true}
+	partialErr := &platform.Error{Provider: "mock", Kind: platform.ErrInvalidRecipient}
+	partialResult := &platform.SendResult{MessageID: "abc123", AcceptedRecipients: []string{"player@example.com"}, RejectedRecipients: []string{"friend@example"}}
+	verify := object.EmailData{Namespace: "mygame", To: "player@example.com", IdempotencyKey: "verify-1"}
+
+	testCases := []struct {
+		name              string
+		emails            []object.EmailData
+		results           []*platform.SendResult
+		errs              []error
+		expectedErrs      []error
+		expectedMessageID []string
+		expectedCalls     int
+	}{
+		{
+			name:              "duplicate returns the original result",
+			emails:            []object.EmailData{verify, verify},
+			results:           []*platform.SendResult{{MessageID: "abc123", AcceptedRecipients: []string{"player@example.com"}}},
+			expectedErrs:      []error{nil, nil},
+			expectedMessageID: []string{"abc123", "abc123"},
+			expectedCalls:     1,
+		},
+		{
+			name:          "email without key is always sent",
+			emails:        []object.EmailData{{Namespace: "mygame", To: "player@example.com"}, {Namespace: "mygame", To: "player@example.com"}},
+			expectedErrs:  []error{nil, nil},
+			expectedCalls: 2,
+		},
+		{
+			name:          "key is scoped by namespace",
+			emails:        []object.EmailData{verify, {Namespace: "othergame", To: "player@example.com", IdempotencyKey: "verify-1"}},
+			expectedErrs:  []error{nil, nil},
+			expectedCalls: 2,
+		},
+		{
+			name:          "failed email is released for the retry",
+			emails:        []object.EmailData{verify, verify, verify},
+			errs:          []error{sendErr},
+			expectedErrs:  []error{sendErr, nil, nil},
+			expectedCalls: 2,
+		},
+		{
+			name:              "partially sent email is completed",
+			emails:            []object.EmailData{verify, verify},
+			results:           []*platform.SendResult{partialResult},
+			errs:              []error{partialErr},
+			expectedErrs:      []error{partialErr, nil},
+			expectedMessageID: []string{"abc123", "abc123"},
+			expectedCalls:     1,
+		},
+		{
+			name:          "email rejected for all recipients is released",
+			emails:        []object.EmailData{verify, verify},
+			results:       []*platform.SendResult{{RejectedRecipients: []string{"player@example.com"}}},
+			errs:          []error{partialErr},
+			expectedErrs:  []error{partialErr, nil},
+			expectedCalls: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			emailSender := &resultEmailSender{results: testCase.results, errs: testCase.errs}
+			idempotentEmailSender := NewIdempotentEmailSender(emailSender, idempotency.NewMemoryStore(time.Minute), time.Hour)
+
+			for i, emailData := range testCase.emails {
+				result, err := idempotentEmailSender.SendEmailWithResult(context.Background(), emailData)
+				if err != testCase.expectedErrs[i] {
+					t.Errorf("email %d: expected error %v, got %v", i, testCase.expectedErrs[i], err)
+				}
+				if testCase.expectedMessageID != nil && (result == nil || result.MessageID != testCase.expectedMessageID[i]) {
+					t.Errorf("email %d: expected message id %s, got %+v", i, testCase.expectedMessageID[i], result)
+				}
+			}
+			if emailSender.calls != testCase.expectedCalls {
+				t.Errorf("expected sent %d times, got %d", testCase.expectedCalls, emailSender.calls)
+			}
+		})
+	}
+}
+
+func TestIdempotentEmailSender_InProgress(t *testing.T) {
+	store := idempotency.NewMemoryStore(time.Minute)
+	emailSender := &resultEmailSender{}
+	idempotentEmailSender := NewIdempotentEmailSender(emailSender, store, time.Hour)
+	idempotentEmailSender.Lease = 20 * time.Millisecond
+
+	// another instance is sending the email
+	if _, err := store.Reserve(context.Background(), "mygame:verify-1", idempotentEmailSender.Lease); err != nil {
+		t.Fatal(err)
+	}
+	emailData := object.EmailData{Namespace: "mygame", To: "player@example.com", IdempotencyKey: "verify-1"}
+	if _, err := idempotentEmailSender.SendEmailWithResult(context.Background(), emailData); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Errorf("expected ErrInProgress, got %v", err)
+	}
+	if emailSender.calls != 0 {
+		t.Errorf("expected not sent while in progress, sent %d times", emailSender.calls)
+	}
+
+	// the other instance crashed, the email is sent after the lease expires
+	time.Sleep(30 * time.Millisecond)
+	if _, err := idempotentEmailSender.SendEmailWithResult(context.Background(), emailData); err != nil {
+		t.Fatal(err)
+	}
+	if emailSender.calls != 1 {
+		t.Errorf("expected sent after the lease, sent %d times", emailSender.calls)
+	}
+}
+
+// failingStore fails to reserve the keys.
+type failingStore struct {
+	idempotency.Store
+	err error
+}
+
+func (s *failingStore) Reserve(ctx context.Context, key string, ttl time.Duration) (*idempotency.Record, error) {
+	return nil, s.err
+}
+
+func TestIdempotentEmailSender_StoreError(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	emailSender := &resultEmailSender{}
+	idempotentEmailSender := NewIdempotentEmailSender(emailSender, &failingStore{err: storeErr}, 0)
+
+	emailData := object.EmailData{Namespace: "mygame", To: "player@example.com", IdempotencyKey: "verify-1"}
+	if _, err := idempotentEmailSender.SendEmailWithResult(context.Background(), emailData); err != storeErr {
+		t.Errorf("expected the store error, got %v", err)
+	}
+	if emailSender.calls != 0 {
+		t.Errorf("expected not sent without the reservation, sent %d times", emailSender.calls)
+	}
+	if idempotentEmailSender.Window != idempotency.DefaultWindow {
+		t.Errorf("expected the default window, got %s", idempotentEmailSender.Window)
+	}
+}
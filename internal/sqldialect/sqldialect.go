@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+// Package sqldialect holds the placeholder and time handling shared by the SQL stores.
+package sqldialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is how a database binds the query parameters and stores the time values.
+type Dialect struct {
+	// DollarBind uses $1, $2, ... instead of the ? placeholders.
+	DollarBind bool
+	// TimeFormat stores the time values as text in this format, empty stores them natively.
+	TimeFormat string
+}
+
+var (
+	Postgres = Dialect{
+		DollarBind: true,
+	}
+
+	SQLite = Dialect{
+		// the fixed width format keeps the text comparison in time order
+		TimeFormat: "2006-01-02 15:04:05.000",
+	}
+)
+
+// Rebind replaces the ? placeholders with $1, $2, ... if the dialect uses them.
+func (d Dialect) Rebind(query string) string {
+	if !d.DollarBind {
+		return query
+	}
+	var builder strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
+
+// TimeValue converts the time to the value stored by the dialect.
+func (d Dialect) TimeValue(t time.Time) interface{} {
+	if d.TimeFormat != "" {
+		return t.UTC().Format(d.TimeFormat)
+	}
+	return t.UTC()
+}
+
+// ScanTime returns a scanner of the time column into t.
+func (d Dialect) ScanTime(t *time.Time) *TimeScanner {
+	format := d.TimeFormat
+	if format == "" {
+		format = time.RFC3339Nano
+	}
+	return &TimeScanner{format: format, time: t}
+}
+
+// TimeScanner scans the time column, stored either natively or as text in the dialect format.
+type TimeScanner struct {
+	format string
+	time   *time.Time
+}
+
+func (s *TimeScanner) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case time.Time:
+		*s.time = value
+		return nil
+	case []byte:
+		return s.parse(string(value))
+	case string:
+		return s.parse(value)
+	default:
+		return fmt.Errorf("unsupported time value %T", src)
+	}
+}
+
+func (s *TimeScanner) parse(value string) error {
+	t, err := time.Parse(s.format, value)
+	if err != nil {
+		return err
+	}
+	*s.time = t
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package object
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+const (
+	AttachmentDispositionAttachment = "attachment"
+	AttachmentDispositionInline     = "inline"
+)
+
+type Attachment struct {
+	Filename string
+	// ContentType of the file, e.g. "application/pdf". Default: application/octet-stream.
+	ContentType string
+	/*
+		Content of the file.
+		If Content is empty, the content is read from Reader when the email is sent.
+	*/
+	Content []byte
+	Reader  io.Reader
+	// Disposition is either "attachment" or "inline". Default: attachment.
+	Disposition string
+	// ContentID is used to reference inline images from the HTML content, e.g. <img src="cid:logo">.
+	ContentID string
+}
+
+// GetContent returns the attachment content, reading it from Reader on the first call.
+func (a *Attachment) GetContent() ([]byte, error) {
+	if a.Content == nil && a.Reader != nil {
+		content, err := ioutil.ReadAll(a.Reader)
+		if err != nil {
+			return nil, err
+		}
+		a.Content = content
+		a.Reader = nil
+	}
+	return a.Content, nil
+}
+
+func (a *Attachment) GetContentType() string {
+	if a.ContentType == "" {
+		return "application/octet-stream"
+	}
+	return a.ContentType
+}
+
+func (a *Attachment) IsInline() bool {
+	return a.Disposition == AttachmentDispositionInline
+}
@@ -17,6 +17,7 @@
 package object
 
 import (
+	"net/mail"
 	"time"
 
 	"github.com/AccelByte/justice-go-common-email/constant"
@@ -39,15 +40,89 @@ type EmailData struct {
 		In "configservice" mode: from-name already configured in Config Service,
 		but this field could be used to replace it if necessary.
 	*/
-	FromName     string
-	To           string
+	FromName string
+	// To is the primary recipient, kept for backward compatibility. Use ToList to send to several recipients.
+	To string
+	// ToList is the additional "To" recipients, sent along with To.
+	ToList       []mail.Address
 	Subject      string
 	ReplyTo      string
 	XMCTemplate  string
 	XMCMergeVars map[string]interface{}
 	Categories   []string
-	// CarbonCopy current this only supported for sendgrid and would need further update for mandrill
-	CarbonCopy []string
+	/*
+		HTMLBody and TextBody are the raw message content.
+		Used by platforms that don't store the template on the provider side (e.g. smtp),
+		and ignored by the template-based platforms when XMCTemplate is specified.
+	*/
+	HTMLBody    string
+	TextBody    string
+	Attachments []Attachment
+	CarbonCopy  []string
+	// Bcc recipients are never visible to the other recipients.
+	Bcc []mail.Address
+	/*
+		IdempotencyKey identifies the email across the retries of the caller, e.g. "verification-<user id>-<code>".
+		With IdempotentEmailSender, the email with the same key and namespace is only sent once within the window.
+	*/
+	IdempotencyKey string
+	/*
+		SendAt schedules the email to be sent later, zero means sending it now.
+		It's scheduled by the provider if supported (e.g. sendgrid, mandrill), otherwise by the email sender locally.
+	*/
+	SendAt time.Time
+}
+
+// IsScheduled returns true if SendAt is in the future.
+func (d *EmailData) IsScheduled() bool {
+	return !d.SendAt.IsZero() && d.SendAt.After(time.Now())
+}
+
+// GetToAddresses returns To followed by ToList.
+func (d *EmailData) GetToAddresses() []mail.Address {
+	addresses := make([]mail.Address, 0, len(d.ToList)+1)
+	if d.To != "" {
+		addresses = append(addresses, mail.Address{Address: d.To})
+	}
+	return append(addresses, d.ToList...)
+}
+
+// GetRecipients returns the addresses of all the recipients, including CarbonCopy and Bcc.
+func (d *EmailData) GetRecipients() []string {
+	recipients := make([]string, 0, len(d.ToList)+len(d.CarbonCopy)+len(d.Bcc)+1)
+	for _, toAddress := range d.GetToAddresses() {
+		recipients = append(recipients, toAddress.Address)
+	}
+	recipients = append(recipients, d.CarbonCopy...)
+	for _, bccAddress := range d.Bcc {
+		recipients = append(recipients, bccAddress.Address)
+	}
+	return recipients
+}
+
+// ReadAttachments reads the content of the attachment readers in advance, e.g. before queueing or persisting the email,
+// since the caller could close or reuse the readers right after. The attachments are copied, the caller's are kept as is.
+func (d *EmailData) ReadAttachments() error {
+	if len(d.Attachments) == 0 {
+		return nil
+	}
+	attachments := make([]Attachment, len(d.Attachments))
+	copy(attachments, d.Attachments)
+	for i := range attachments {
+		if _, err := attachments[i].GetContent(); err != nil {
+			return err
+		}
+	}
+	d.Attachments = attachments
+	return nil
+}
+
+// FormatAddress formats the address as "Name <address>", or the bare address if it has no name.
+func FormatAddress(address mail.Address) string {
+	if address.Name == "" {
+		return address.Address
+	}
+	return address.String()
 }
 
 func (d *EmailData) SetTemplateAdditionalData() {
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package object
+
+import "net/mail"
+
+// Recipient is a recipient of the batch email, with its own merge vars.
+type Recipient struct {
+	Address mail.Address
+	// MergeVars override XMCMergeVars of the batch email for this recipient.
+	MergeVars map[string]interface{}
+}
+
+// GetMergeVars returns XMCMergeVars of the email overridden by the merge vars of the recipient.
+func (r Recipient) GetMergeVars(emailData EmailData) map[string]interface{} {
+	mergeVars := make(map[string]interface{}, len(emailData.XMCMergeVars)+len(r.MergeVars))
+	for key, value := range emailData.XMCMergeVars {
+		mergeVars[key] = value
+	}
+	for key, value := range r.MergeVars {
+		mergeVars[key] = value
+	}
+	return mergeVars
+}
+
+// ForRecipient returns the email sent to the recipient alone, with its merge vars.
+// The recipients of the batch email itself (To, ToList, CarbonCopy and Bcc) are ignored.
+func (d EmailData) ForRecipient(recipient Recipient) EmailData {
+	d.XMCMergeVars = recipient.GetMergeVars(d)
+	d.To = recipient.Address.Address
+	d.ToList = nil
+	if recipient.Address.Name != "" {
+		d.To = ""
+		d.ToList = []mail.Address{recipient.Address}
+	}
+	d.CarbonCopy = nil
+	d.Bcc = nil
+	return d
+}
+
+// GetRecipientAddresses returns the addresses of the recipients.
+func GetRecipientAddresses(recipients []Recipient) []string {
+	addresses := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		addresses = append(addresses, recipient.Address.Address)
+	}
+	return addresses
+}
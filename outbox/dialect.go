@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package outbox
+
+import (
+	"fmt"
+
+	"github.com/AccelByte/justice-go-common-email/internal/sqldialect"
+)
+
+// Dialect is the SQL dialect of the outbox table.
+type Dialect struct {
+	Name string
+
+	createTable string
+	skipLocked  string
+	base        sqldialect.Dialect
+}
+
+var (
+	DialectPostgres = Dialect{
+		Name: "postgres",
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+	id BIGSERIAL PRIMARY KEY,
+	namespace VARCHAR(255) NOT NULL DEFAULT '',
+	email_data TEXT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	provider_message_id VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+)`,
+		// concurrent dispatchers claim different messages instead of waiting for each other
+		skipLocked: " FOR UPDATE SKIP LOCKED",
+		base:       sqldialect.Postgres,
+	}
+
+	// DialectSQLite requires SQLite 3.35 or later for the RETURNING clause.
+	DialectSQLite = Dialect{
+		Name: "sqlite",
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	namespace TEXT NOT NULL DEFAULT '',
+	email_data TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TEXT NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	provider_message_id TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+)`,
+		base: sqldialect.SQLite,
+	}
+)
+
+func (d Dialect) createTableQueries(table string) []string {
+	return []string{
+		fmt.Sprintf(d.createTable, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_status_next_attempt_at_idx ON %s (status, next_attempt_at)", table, table),
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	emailsender "github.com/AccelByte/justice-go-common-email"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultPollInterval   = time.Second
+	DefaultBatchSize      = 10
+	DefaultMaxAttempts    = 10
+	DefaultInitialBackoff = 10 * time.Second
+	DefaultMaxBackoff     = time.Hour
+	DefaultLease          = time.Minute
+)
+
+// Dispatcher polls the outbox and sends the emails through the email sender.
+// The failed emails are retried with exponential backoff until MaxAttempts,
+// except the emails rejected by the provider as invalid which are marked failed immediately.
+type Dispatcher struct {
+	Store          *Store
+	EmailSender    emailsender.EmailSender
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Lease is how long a claimed email is reserved for this dispatcher before another one could claim it again.
+	// It must be longer than sending the whole batch.
+	Lease time.Duration
+}
+
+func NewDispatcher(store *Store, emailSender emailsender.EmailSender) *Dispatcher {
+	return &Dispatcher{
+		Store:          store,
+		EmailSender:    emailSender,
+		PollInterval:   DefaultPollInterval,
+		BatchSize:      DefaultBatchSize,
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Lease:          DefaultLease,
+	}
+}
+
+// Run dispatches the outbox until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		count, err := d.DispatchOnce(ctx)
+		if err != nil {
+			logrus.Errorf("fail dispatch email outbox. error: %v", err)
+		}
+		// keep going without waiting while the outbox is backlogged
+		if err == nil && count >= d.BatchSize {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-time.After(d.PollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// DispatchOnce claims a batch of emails, sends them and returns the count of emails claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	messages, err := d.Store.Claim(ctx, d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, message := range messages {
+		if err = d.dispatch(ctx, message); err != nil {
+			return len(messages), err
+		}
+	}
+	return len(messages), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, message Message) error {
+	result, errSend := emailsender.SendEmailWithResult(ctx, d.EmailSender, message.EmailData)
+	if errSend == nil {
+		var messageID string
+		if result != nil {
+			messageID = result.MessageID
+		}
+		return d.Store.MarkDelivered(ctx, message.ID, messageID)
+	}
+
+	if message.Attempts >= d.MaxAttempts || isPermanentError(errSend) {
+		logrus.Errorf("fail send outbox email %d to %s, giving up after %d attempts. error: %v",
+			message.ID, message.EmailData.To, message.Attempts, errSend)
+		return d.Store.MarkFailed(ctx, message.ID, errSend.Error())
+	}
+	backoff := d.getBackoff(message.Attempts)
+	if retryAfter := platform.GetRetryAfter(errSend); retryAfter > backoff {
+		backoff = retryAfter
+	}
+	logrus.Warnf("fail send outbox email %d to %s, retrying in %s. error: %v", message.ID, message.EmailData.To, backoff, errSend)
+	return d.Store.MarkRetry(ctx, message.ID, time.Now().Add(backoff), errSend.Error())
+}
+
+func (d *Dispatcher) getBackoff(attempts int) time.Duration {
+	backoff := d.InitialBackoff
+	for i := 1; i < attempts && backoff < d.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.MaxBackoff {
+		backoff = d.MaxBackoff
+	}
+	return backoff
+}
+
+// isPermanentError returns true if sending the same email again would fail the same way.
+func isPermanentError(err error) bool {
+	return errors.Is(err, platform.ErrInvalidRecipient) || errors.Is(err, platform.ErrBadRequest) ||
+		errors.Is(err, emailsender.ErrRecipientSuppressed)
+}
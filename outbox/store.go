@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+const (
+	DefaultTable = "email_outbox"
+
+	StatusPending   = "pending"
+	StatusSending   = "sending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Execer is satisfied by both *sql.DB and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Message is the email claimed from the outbox.
+type Message struct {
+	ID        int64
+	EmailData object.EmailData
+	// Attempts includes the current attempt.
+	Attempts int
+}
+
+// Store persists the emails in the outbox table.
+type Store struct {
+	DB      *sql.DB
+	Dialect Dialect
+	Table   string
+}
+
+func NewStore(db *sql.DB, dialect Dialect) *Store {
+	return &Store{
+		DB:      db,
+		Dialect: dialect,
+		Table:   DefaultTable,
+	}
+}
+
+// CreateTable creates the outbox table and its index if they don't exist yet.
+func (s *Store) CreateTable(ctx context.Context) error {
+	for _, query := range s.Dialect.createTableQueries(s.Table) {
+		if _, err := s.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("fail create email outbox table: %w", err)
+		}
+	}
+	return nil
+}
+
+// Enqueue inserts the email into the outbox using the given transaction (or database),
+// so the email is only sent if the caller's transaction is committed.
+func (s *Store) Enqueue(ctx context.Context, execer Execer, emailData object.EmailData) (int64, error) {
+	// the attachment readers can't be persisted, so their content is read in advance
+	if err := emailData.ReadAttachments(); err != nil {
+		return 0, err
+	}
+	data, err := encodeEmailData(emailData)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	// the scheduled email is dispatched at its SendAt
+	nextAttemptAt := now
+	if emailData.IsScheduled() {
+		nextAttemptAt = emailData.SendAt
+	}
+	query := s.Dialect.base.Rebind(fmt.Sprintf(
+		"INSERT INTO %s (namespace, email_data, status, attempts, next_attempt_at, created_at, updated_at) "+
+			"VALUES (?, ?, ?, 0, ?, ?, ?) RETURNING id", s.Table))
+	var id int64
+	if err = execer.QueryRowContext(ctx, query, emailData.Namespace, data, StatusPending,
+		s.Dialect.base.TimeValue(nextAttemptAt), s.Dialect.base.TimeValue(now), s.Dialect.base.TimeValue(now)).Scan(&id); err != nil {
+		return 0, fmt.Errorf("fail insert email into outbox: %w", err)
+	}
+	return id, nil
+}
+
+// Claim marks up to limit emails due to be sent as sending for the lease duration and returns them.
+// Emails still sending after the lease, e.g. if the dispatcher crashed, are claimed again.
+func (s *Store) Claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error) {
+	now := time.Now()
+	query := s.Dialect.base.Rebind(fmt.Sprintf(
+		"UPDATE %s SET status = ?, attempts = attempts + 1, next_attempt_at = ?, updated_at = ? "+
+			"WHERE id IN (SELECT id FROM %s WHERE status IN (?, ?) AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?%s) "+
+			"RETURNING id, email_data, attempts", s.Table, s.Table, s.Dialect.skipLocked))
+	rows, err := s.DB.QueryContext(ctx, query,
+		StatusSending, s.Dialect.base.TimeValue(now.Add(lease)), s.Dialect.base.TimeValue(now),
+		StatusPending, StatusSending, s.Dialect.base.TimeValue(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("fail claim emails from outbox: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var messages []Message
+	for rows.Next() {
+		var message Message
+		var data string
+		if err = rows.Scan(&message.ID, &data, &message.Attempts); err != nil {
+			return nil, err
+		}
+		if message.EmailData, err = decodeEmailData(data); err != nil {
+			return nil, fmt.Errorf("fail decode outbox email %d: %w", message.ID, err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (s *Store) MarkDelivered(ctx context.Context, id int64, providerMessageID string) error {
+	return s.update(ctx, id, "status = ?, provider_message_id = ?, last_error = ''", StatusDelivered, providerMessageID)
+}
+
+// MarkRetry puts the email back as pending to be sent again at nextAttemptAt.
+func (s *Store) MarkRetry(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	return s.update(ctx, id, "status = ?, next_attempt_at = ?, last_error = ?",
+		StatusPending, s.Dialect.base.TimeValue(nextAttemptAt), lastError)
+}
+
+func (s *Store) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	return s.update(ctx, id, "status = ?, last_error = ?", StatusFailed, lastError)
+}
+
+func (s *Store) update(ctx context.Context, id int64, set string, args ...interface{}) error {
+	query := s.Dialect.base.Rebind(fmt.Sprintf("UPDATE %s SET %s, updated_at = ? WHERE id = ?", s.Table, set))
+	args = append(args, s.Dialect.base.TimeValue(time.Now()), id)
+	if _, err := s.DB.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("fail update outbox email %d: %w", id, err)
+	}
+	return nil
+}
+
+func encodeEmailData(emailData object.EmailData) (string, error) {
+	data, err := json.Marshal(emailData)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeEmailData decodes the numbers of XMCMergeVars as json.Number instead of float64,
+// so e.g. 1234567 is sent as is instead of 1.234567e+06.
+func decodeEmailData(data string) (object.EmailData, error) {
+	var emailData object.EmailData
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&emailData)
+	return emailData, err
+}
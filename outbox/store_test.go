@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+func TestEmailDataRoundTrip(t *testing.T) {
+	emailData := object.EmailData{
+		Namespace:   "mygame",
+		To:          "player@example.com",
+		XMCTemplate: "verify",
+		XMCMergeVars: map[string]interface{}{
+			"code":    1234567,
+			"balance": 12.5,
+			"name":    "Player",
+			"items":   []interface{}{1, "sword"},
+		},
+		Attachments: []object.Attachment{{Filename: "terms.txt", Content: []byte("terms")}},
+		SendAt:      time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := encodeEmailData(emailData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeEmailData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMergeVars := map[string]string{
+		"code":    "1234567",
+		"balance": "12.5",
+		"name":    "Player",
+		"items":   "[1 sword]",
+	}
+	for key, expected := range expectedMergeVars {
+		// the merge vars are formatted with %v by e.g. Mandrill
+		if value := fmt.Sprintf("%v", decoded.XMCMergeVars[key]); value != expected {
+			t.Errorf("expected merge var %s %q, got %q", key, expected, value)
+		}
+	}
+	if code, ok := decoded.XMCMergeVars["code"].(json.Number); !ok || code.String() != "1234567" {
+		t.Errorf("expected code decoded as json.Number, got %T", decoded.XMCMergeVars["code"])
+	}
+	if string(decoded.Attachments[0].Content) != "terms" {
+		t.Errorf("expected attachment content, got %q", decoded.Attachments[0].Content)
+	}
+	if !decoded.SendAt.Equal(emailData.SendAt) {
+		t.Errorf("expected SendAt %s, got %s", emailData.SendAt, decoded.SendAt)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+var ErrBatchNotSupported = errors.New("sender platform doesn't support batch sending")
+
+// BatchSenderPlatform is implemented by the sender platforms able to send an email to many recipients in a request,
+// each recipient with its own merge vars, e.g. SendGrid personalizations.
+type BatchSenderPlatform interface {
+	SenderPlatform
+	// MaxBatchSize returns the max recipients per request, 0 if batch isn't supported.
+	MaxBatchSize() int
+	// SendBatch sends the email to up to MaxBatchSize recipients in a request.
+	SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*SendResult, error)
+}
+
+// BatchResult is the result of a chunk of the batch recipients.
+type BatchResult struct {
+	Recipients []string
+	Result     *SendResult
+	Err        error
+}
+
+// MaxBatchSize returns the max recipients per request of the sender platform, 0 if batch isn't supported.
+func MaxBatchSize(senderPlatform SenderPlatform) int {
+	if batchSenderPlatform, ok := senderPlatform.(BatchSenderPlatform); ok {
+		return batchSenderPlatform.MaxBatchSize()
+	}
+	return 0
+}
+
+// SendBatchChunk sends the email to the recipients in a request, they must not exceed MaxBatchSize.
+func SendBatchChunk(ctx context.Context, senderPlatform SenderPlatform, provider string, emailData object.EmailData,
+	recipients []object.Recipient) (*SendResult, error) {
+	batchSenderPlatform, ok := senderPlatform.(BatchSenderPlatform)
+	if !ok || batchSenderPlatform.MaxBatchSize() == 0 {
+		return nil, ErrBatchNotSupported
+	}
+	result, err := batchSenderPlatform.SendBatch(ctx, emailData, recipients)
+	if result != nil && result.Provider == "" {
+		result.Provider = provider
+	}
+	return result, err
+}
+
+// SendBatch sends the email to the recipients, chunked by MaxBatchSize of the sender platform,
+// and returns the result of every chunk. If the platform doesn't support batch, the email is sent to each recipient separately.
+// The recipients of the email itself are ignored.
+func SendBatch(ctx context.Context, senderPlatform SenderPlatform, provider string, emailData object.EmailData,
+	recipients []object.Recipient) []BatchResult {
+	var results []BatchResult
+	chunkSize := MaxBatchSize(senderPlatform)
+	if chunkSize == 0 {
+		for _, recipient := range recipients {
+			result, err := SendWithResult(ctx, senderPlatform, provider, emailData.ForRecipient(recipient))
+			results = append(results, BatchResult{Recipients: []string{recipient.Address.Address}, Result: result, Err: err})
+		}
+		return results
+	}
+
+	for start := 0; start < len(recipients); start += chunkSize {
+		end := start + chunkSize
+		if end > len(recipients) {
+			end = len(recipients)
+		}
+		chunk := recipients[start:end]
+		result, err := SendBatchChunk(ctx, senderPlatform, provider, emailData, chunk)
+		results = append(results, BatchResult{Recipients: object.GetRecipientAddresses(chunk), Result: result, Err: err})
+	}
+	return results
+}
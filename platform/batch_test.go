@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+// recordingPlatform records the emails sent one by one, failing the recipients in errs.
+type recordingPlatform struct {
+	sent []object.EmailData
+	errs map[string]error
+}
+
+func (p *recordingPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	p.sent = append(p.sent, emailData)
+	return p.errs[emailData.To]
+}
+
+// recordingBatchPlatform records the recipients of each batch request, failing the requests in errs by index.
+type recordingBatchPlatform struct {
+	recordingPlatform
+	maxBatchSize int
+	batches      [][]string
+	batchErrs    map[int]error
+}
+
+func (p *recordingBatchPlatform) MaxBatchSize() int {
+	return p.maxBatchSize
+}
+
+func (p *recordingBatchPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*SendResult, error) {
+	p.batches = append(p.batches, object.GetRecipientAddresses(recipients))
+	if err := p.batchErrs[len(p.batches)-1]; err != nil {
+		return nil, err
+	}
+	return &SendResult{AcceptedRecipients: object.GetRecipientAddresses(recipients)}, nil
+}
+
+func newRecipients(count int) []object.Recipient {
+	recipients := make([]object.Recipient, count)
+	for i := range recipients {
+		recipients[i] = object.Recipient{Address: mail.Address{Address: fmt.Sprintf("player%d@example.com", i)}}
+	}
+	return recipients
+}
+
+func TestSendBatch_Chunks(t *testing.T) {
+	chunkErr := &Error{Provider: "batch", Kind: ErrProviderUnavailable, Retryable: true}
+	testCases := []struct {
+		name            string
+		recipients      int
+		maxBatchSize    int
+		batchErrs       map[int]error
+		expectedBatches []int
+	}{
+		{
+			name:            "single chunk",
+			recipients:      3,
+			maxBatchSize:    1000,
+			expectedBatches: []int{3},
+		},
+		{
+			name:            "exact chunks",
+			recipients:      4,
+			maxBatchSize:    2,
+			expectedBatches: []int{2, 2},
+		},
+		{
+			name:            "last chunk is smaller",
+			recipients:      5,
+			maxBatchSize:    2,
+			expectedBatches: []int{2, 2, 1},
+		},
+		{
+			name:            "failed chunk doesn't stop the next ones",
+			recipients:      5,
+			maxBatchSize:    2,
+			batchErrs:       map[int]error{1: chunkErr},
+			expectedBatches: []int{2, 2, 1},
+		},
+		{
+			name:         "no recipient",
+			maxBatchSize: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			senderPlatform := &recordingBatchPlatform{maxBatchSize: testCase.maxBatchSize, batchErrs: testCase.batchErrs}
+			recipients := newRecipients(testCase.recipients)
+			results := SendBatch(context.Background(), senderPlatform, "batch", object.EmailData{XMCTemplate: "newsletter"}, recipients)
+
+			if len(results) != len(testCase.expectedBatches) || len(senderPlatform.batches) != len(testCase.expectedBatches) {
+				t.Fatalf("expected %d chunks, got %d results of %d requests", len(testCase.expectedBatches), len(results), len(senderPlatform.batches))
+			}
+			start := 0
+			for i, size := range testCase.expectedBatches {
+				expectedRecipients := object.GetRecipientAddresses(recipients[start : start+size])
+				start += size
+				if !reflect.DeepEqual(results[i].Recipients, expectedRecipients) || !reflect.DeepEqual(senderPlatform.batches[i], expectedRecipients) {
+					t.Errorf("chunk %d: expected recipients %v, got %v", i, expectedRecipients, results[i].Recipients)
+				}
+				if expectedErr := testCase.batchErrs[i]; expectedErr != nil {
+					if results[i].Err != expectedErr || results[i].Result != nil {
+						t.Errorf("chunk %d: expected the chunk error, got %+v", i, results[i])
+					}
+					continue
+				}
+				if results[i].Err != nil || results[i].Result.Provider != "batch" {
+					t.Errorf("chunk %d: expected the result of the provider, got %+v", i, results[i])
+				}
+			}
+			if len(senderPlatform.sent) != 0 {
+				t.Errorf("expected nothing sent one by one, got %d", len(senderPlatform.sent))
+			}
+		})
+	}
+}
+
+func TestSendBatch_PerRecipient(t *testing.T) {
+	sendErr := errors.New("mailbox unavailable")
+	testCases := []struct {
+		name     string
+		platform SenderPlatform
+	}{
+		{name: "platform without batch", platform: &recordingPlatform{errs: map[string]error{"b@example.com": sendErr}}},
+		{name: "batch disabled", platform: &recordingBatchPlatform{recordingPlatform: recordingPlatform{errs: map[string]error{"b@example.com": sendErr}}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			emailData := object.EmailData{
+				To:           "ignored@example.com",
+				CarbonCopy:   []string{"ignored@mygame.com"},
+				Bcc:          []mail.Address{{Address: "ignored@mygame.com"}},
+				XMCTemplate:  "newsletter",
+				XMCMergeVars: map[string]interface{}{"game": "My Game", "name": "player"},
+			}
+			recipients := []object.Recipient{
+				{Address: mail.Address{Address: "a@example.com"}, MergeVars: map[string]interface{}{"name": "A"}},
+				{Address: mail.Address{Address: "b@example.com"}},
+				{Address: mail.Address{Name: "C", Address: "c@example.com"}},
+			}
+			results := SendBatch(context.Background(), testCase.platform, "batch", emailData, recipients)
+
+			if len(results) != 3 {
+				t.Fatalf("expected a result per recipient, got %d", len(results))
+			}
+			for i, recipient := range recipients {
+				if len(results[i].Recipients) != 1 || results[i].Recipients[0] != recipient.Address.Address {
+					t.Errorf("expected result of %s, got %v", recipient.Address.Address, results[i].Recipients)
+				}
+			}
+			if results[0].Err != nil || results[1].Err != sendErr || results[2].Err != nil {
+				t.Errorf("expected only the second recipient failed, got %v, %v, %v", results[0].Err, results[1].Err, results[2].Err)
+			}
+
+			var sent []object.EmailData
+			switch senderPlatform := testCase.platform.(type) {
+			case *recordingPlatform:
+				sent = senderPlatform.sent
+			case *recordingBatchPlatform:
+				sent = senderPlatform.sent
+				if len(senderPlatform.batches) != 0 {
+					t.Errorf("expected no batch request, got %d", len(senderPlatform.batches))
+				}
+			}
+			expected := []object.EmailData{
+				{To: "a@example.com", XMCTemplate: "newsletter", XMCMergeVars: map[string]interface{}{"game": "My Game", "name": "A"}},
+				{To: "b@example.com", XMCTemplate: "newsletter", XMCMergeVars: map[string]interface{}{"game": "My Game", "name": "player"}},
+				{ToList: []mail.Address{{Name: "C", Address: "c@example.com"}}, XMCTemplate: "newsletter", XMCMergeVars: map[string]interface{}{"game": "My Game", "name": "player"}},
+			}
+			if !reflect.DeepEqual(sent, expected) {
+				t.Errorf("expected sent %+v, got %+v", expected, sent)
+			}
+		})
+	}
+}
+
+func TestSendBatchChunk_NotSupported(t *testing.T) {
+	recipients := newRecipients(1)
+	if _, err := SendBatchChunk(context.Background(), &recordingPlatform{}, "batch", object.EmailData{}, recipients); !errors.Is(err, ErrBatchNotSupported) {
+		t.Errorf("expected ErrBatchNotSupported, got %v", err)
+	}
+	if _, err := SendBatchChunk(context.Background(), &recordingBatchPlatform{}, "batch", object.EmailData{}, recipients); !errors.Is(err, ErrBatchNotSupported) {
+		t.Errorf("expected ErrBatchNotSupported of the disabled batch, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+
+	DefaultFailureRateThreshold = 0.5
+	DefaultMinRequests          = 10
+	DefaultWindow               = time.Minute
+	DefaultCoolDown             = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type Settings struct {
+	// FailureRateThreshold is the failure rate within the window which opens the circuit, between 0 and 1.
+	FailureRateThreshold float64
+	// MinRequests is the minimum requests within the window before the failure rate is evaluated.
+	MinRequests int
+	// Window is the duration the failure rate is counted over.
+	Window time.Duration
+	// CoolDown is the duration the circuit stays open before a trial request is allowed.
+	CoolDown time.Duration
+}
+
+// Breaker tracks the provider failures and opens the circuit once the failure rate reaches the threshold.
+// After the cool-down, the circuit is half-open: a single trial request is allowed,
+// which closes the circuit on success or opens it again on failure.
+type Breaker struct {
+	Settings Settings
+
+	lock          sync.Mutex
+	state         State
+	windowStart   time.Time
+	requests      int
+	failures      int
+	openedAt      time.Time
+	trialInFlight bool
+	// generation is incremented on every state change, so the late results of the requests allowed before are ignored
+	generation uint64
+	// now returns the current time, replaced by the tests
+	now func() time.Time
+}
+
+func NewBreaker(settings Settings) *Breaker {
+	if settings.FailureRateThreshold <= 0 || settings.FailureRateThreshold > 1 {
+		settings.FailureRateThreshold = DefaultFailureRateThreshold
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = DefaultMinRequests
+	}
+	if settings.Window <= 0 {
+		settings.Window = DefaultWindow
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = DefaultCoolDown
+	}
+	return &Breaker{
+		Settings:    settings,
+		state:       StateClosed,
+		windowStart: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// State returns the current circuit state, e.g. for health checks.
+func (b *Breaker) State() State {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.Settings.CoolDown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow returns ErrCircuitOpen if the request is not allowed.
+// Every allowed request must be followed by Record with the returned generation.
+func (b *Breaker) Allow() (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.Settings.CoolDown {
+			return 0, ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.generation++
+		fallthrough
+	case StateHalfOpen:
+		if b.trialInFlight {
+			return 0, ErrCircuitOpen
+		}
+		b.trialInFlight = true
+	default:
+		if now.Sub(b.windowStart) >= b.Settings.Window {
+			b.windowStart = now
+			b.requests = 0
+			b.failures = 0
+		}
+	}
+	return b.generation, nil
+}
+
+// Record records the result of the request allowed in the given generation.
+// The result of a request allowed before the last state change is ignored, e.g. a slow request allowed
+// while the circuit was closed isn't taken as the trial of the half-open circuit.
+// Only provider failures are counted: retryable, unavailable and unauthorized errors,
+// while e.g. invalid recipients don't tell anything about the provider health.
+func (b *Breaker) Record(generation uint64, err error) {
+	failed := isProviderFailure(err)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+	if b.state == StateHalfOpen {
+		b.trialInFlight = false
+		if failed {
+			b.open()
+		} else {
+			b.close()
+		}
+		return
+	}
+
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.state == StateClosed && b.requests >= b.Settings.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.Settings.FailureRateThreshold {
+		b.open()
+	}
+}
+
+func (b *Breaker) open() {
+	if b.state != StateOpen {
+		logrus.Warnf("circuit breaker is open, sender platform is suspended for %s", b.Settings.CoolDown)
+	}
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.generation++
+}
+
+func (b *Breaker) close() {
+	logrus.Infof("circuit breaker is closed, sender platform is resumed")
+	b.state = StateClosed
+	b.generation++
+	b.windowStart = b.now()
+	b.requests = 0
+	b.failures = 0
+}
+
+func isProviderFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	return platform.IsRetryable(err) ||
+		errors.Is(err, platform.ErrProviderUnavailable) ||
+		errors.Is(err, platform.ErrUnauthorized)
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// step advances the clock, then asks Allow and records the result if it's allowed, unless skipRecord is set.
+// With recordOnly, the result of the request allowed at the step recordOf is recorded without asking Allow.
+type step struct {
+	advance    time.Duration
+	allowErr   error
+	result     error
+	skipRecord bool
+	recordOnly bool
+	recordOf   int
+	state      State
+}
+
+var (
+	errUnavailable      = &platform.Error{Provider: "test", Kind: platform.ErrProviderUnavailable, Retryable: true}
+	errUnauthorized     = &platform.Error{Provider: "test", Kind: platform.ErrUnauthorized}
+	errInvalidRecipient = &platform.Error{Provider: "test", Kind: platform.ErrInvalidRecipient}
+)
+
+func success(state State) step {
+	return step{state: state}
+}
+
+func failure(err error, state State) step {
+	return step{result: err, state: state}
+}
+
+func TestBreaker(t *testing.T) {
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "stays closed below min requests",
+			steps: []step{
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+			},
+		},
+		{
+			name: "opens at failure rate threshold",
+			steps: []step{
+				success(StateClosed),
+				success(StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{allowErr: ErrCircuitOpen, state: StateOpen},
+			},
+		},
+		{
+			name: "stays closed below failure rate threshold",
+			steps: []step{
+				success(StateClosed),
+				success(StateClosed),
+				success(StateClosed),
+				failure(errUnavailable, StateClosed),
+				success(StateClosed),
+			},
+		},
+		{
+			name: "counts unauthorized as failure",
+			steps: []step{
+				failure(errUnauthorized, StateClosed),
+				failure(errUnauthorized, StateClosed),
+				failure(errUnauthorized, StateClosed),
+				failure(errUnauthorized, StateOpen),
+			},
+		},
+		{
+			name: "ignores invalid recipient",
+			steps: []step{
+				failure(errInvalidRecipient, StateClosed),
+				failure(errInvalidRecipient, StateClosed),
+				failure(errInvalidRecipient, StateClosed),
+				failure(errInvalidRecipient, StateClosed),
+				failure(errors.New("not a platform error"), StateClosed),
+			},
+		},
+		{
+			name: "resets counts after window",
+			steps: []step{
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				{advance: time.Minute, result: errUnavailable, state: StateClosed},
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+			},
+		},
+		{
+			name: "allows single trial after cool-down and closes on success",
+			steps: []step{
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{advance: 29 * time.Second, allowErr: ErrCircuitOpen, state: StateOpen},
+				{advance: time.Second, skipRecord: true, state: StateHalfOpen},
+				{allowErr: ErrCircuitOpen, state: StateHalfOpen},
+				{recordOnly: true, recordOf: 5, state: StateClosed},
+				failure(errUnavailable, StateClosed),
+			},
+		},
+		{
+			name: "ignores late result of request allowed before half-open",
+			steps: []step{
+				{skipRecord: true, state: StateClosed},
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{advance: 30 * time.Second, skipRecord: true, state: StateHalfOpen},
+				{recordOnly: true, recordOf: 0, state: StateHalfOpen},
+				{allowErr: ErrCircuitOpen, state: StateHalfOpen},
+				{recordOnly: true, recordOf: 5, result: errUnavailable, state: StateOpen},
+			},
+		},
+		{
+			name: "ignores late failure of request allowed before reopening",
+			steps: []step{
+				{skipRecord: true, state: StateClosed},
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{advance: 30 * time.Second, state: StateClosed},
+				{recordOnly: true, recordOf: 0, result: errUnavailable, state: StateClosed},
+				// the late failure would reach the threshold at the 4th request
+				failure(errUnavailable, StateClosed),
+				success(StateClosed),
+				success(StateClosed),
+			},
+		},
+		{
+			name: "reopens on trial failure",
+			steps: []step{
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{advance: 30 * time.Second, result: errUnavailable, state: StateOpen},
+				{advance: 29 * time.Second, allowErr: ErrCircuitOpen, state: StateOpen},
+				{advance: time.Second, state: StateClosed},
+			},
+		},
+		{
+			name: "reports half-open after cool-down before the trial",
+			steps: []step{
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateClosed),
+				failure(errUnavailable, StateOpen),
+				{advance: 30 * time.Second, skipRecord: true, state: StateHalfOpen},
+				{recordOnly: true, recordOf: 4, result: errUnavailable, state: StateOpen},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)}
+			breaker := NewBreaker(Settings{
+				FailureRateThreshold: 0.5,
+				MinRequests:          4,
+				Window:               time.Minute,
+				CoolDown:             30 * time.Second,
+			})
+			breaker.now = clock.Now
+			breaker.windowStart = clock.Now()
+
+			generations := make(map[int]uint64)
+			for i, s := range tc.steps {
+				clock.now = clock.now.Add(s.advance)
+				if s.recordOnly {
+					breaker.Record(generations[s.recordOf], s.result)
+				} else {
+					generation, err := breaker.Allow()
+					if err != s.allowErr {
+						t.Fatalf("step %d: expected Allow error %v, got %v", i, s.allowErr, err)
+					}
+					generations[i] = generation
+					if err == nil && !s.skipRecord {
+						breaker.Record(generation, s.result)
+					}
+				}
+				if state := breaker.State(); state != s.state {
+					t.Fatalf("step %d: expected state %s, got %s", i, s.state, state)
+				}
+			}
+		})
+	}
+}
+
+func TestBreaker_State(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)}
+	breaker := NewBreaker(Settings{MinRequests: 1, CoolDown: 30 * time.Second})
+	breaker.now = clock.Now
+	breaker.windowStart = clock.Now()
+
+	generation, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("expected allowed, got %v", err)
+	}
+	breaker.Record(generation, errUnavailable)
+	if state := breaker.State(); state != StateOpen {
+		t.Fatalf("expected open, got %s", state)
+	}
+	// the cool-down is over, so the next request would be the trial
+	clock.now = clock.now.Add(30 * time.Second)
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s", state)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"sync"
+)
+
+// Group holds a breaker per key, e.g. per provider API key,
+// so the failures of one key don't open the circuit of the others.
+type Group struct {
+	Settings Settings
+
+	lock     sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewGroup(settings Settings) *Group {
+	return &Group{
+		Settings: settings,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get returns the breaker of the key, creating it if it doesn't exist yet.
+func (g *Group) Get(key string) *Breaker {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	breaker, found := g.breakers[key]
+	if !found {
+		breaker = NewBreaker(g.Settings)
+		g.breakers[key] = breaker
+	}
+	return breaker
+}
+
+// States returns the current circuit state of every key, e.g. for health checks.
+func (g *Group) States() map[string]State {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	states := make(map[string]State, len(g.breakers))
+	for key, breaker := range g.breakers {
+		states[key] = breaker.State()
+	}
+	return states
+}
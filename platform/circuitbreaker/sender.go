@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// SenderPlatform stops calling the wrapped sender platform while its circuit is open.
+// The open circuit fails fast with platform.ErrProviderUnavailable, so the failover chain moves on to the next platform.
+type SenderPlatform struct {
+	ID             string
+	SenderPlatform platform.SenderPlatform
+	Breaker        *Breaker
+}
+
+func NewCircuitBreakerSenderPlatform(id string, senderPlatform platform.SenderPlatform, breaker *Breaker) platform.SenderPlatform {
+	return &SenderPlatform{
+		ID:             id,
+		SenderPlatform: senderPlatform,
+		Breaker:        breaker,
+	}
+}
+
+func (e SenderPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e SenderPlatform) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	generation, err := e.Breaker.Allow()
+	if err != nil {
+		return nil, e.newCircuitOpenError(err)
+	}
+	result, err := platform.SendWithResult(ctx, e.SenderPlatform, e.ID, emailData)
+	e.Breaker.Record(generation, err)
+	return result, err
+}
+
+func (e SenderPlatform) MaxBatchSize() int {
+	return platform.MaxBatchSize(e.SenderPlatform)
+}
+
+func (e SenderPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	generation, err := e.Breaker.Allow()
+	if err != nil {
+		return nil, e.newCircuitOpenError(err)
+	}
+	result, err := platform.SendBatchChunk(ctx, e.SenderPlatform, e.ID, emailData, recipients)
+	e.Breaker.Record(generation, err)
+	return result, err
+}
+
+func (e SenderPlatform) newCircuitOpenError(err error) error {
+	return &platform.Error{
+		Provider: e.ID,
+		Message:  err.Error(),
+		Kind:     platform.ErrProviderUnavailable,
+		Err:      err,
+	}
+}
+
+func (e SenderPlatform) CanSendTemplate() bool {
+	return platform.CanSendTemplate(e.SenderPlatform)
+}
+
+func (e SenderPlatform) CanSchedule(sendAt time.Time) bool {
+	return platform.CanSchedule(e.SenderPlatform, sendAt)
+}
+
+func (e SenderPlatform) CancelScheduled(ctx context.Context, scheduleID string) error {
+	return platform.CancelScheduled(ctx, e.SenderPlatform, scheduleID)
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnauthorized        = errors.New("sender platform rejected the credentials")
+	ErrRateLimited         = errors.New("sender platform rate limit exceeded")
+	ErrInvalidRecipient    = errors.New("sender platform rejected the recipient")
+	ErrBadRequest          = errors.New("sender platform rejected the request")
+	ErrProviderUnavailable = errors.New("sender platform is unavailable")
+)
+
+// Error is returned by the sender platforms when the provider fails to send the email.
+// Use errors.Is with the Err* sentinel errors above to check the failure kind.
+type Error struct {
+	Provider string
+	// StatusCode is the HTTP status code, or the SMTP reply code for the SMTP based platforms.
+	StatusCode int
+	// Code is the provider specific error code, e.g. Mandrill's "Invalid_Key".
+	Code    string
+	Message string
+	// Retryable tells whether sending the same email again could succeed.
+	Retryable bool
+	// Recipients are the recipients affected by the error, if known.
+	Recipients []string
+	// RetryAfter is the delay requested by the provider before retrying, from the Retry-After header.
+	RetryAfter time.Duration
+	// Kind is one of the Err* sentinel errors, or nil if the error is not classified.
+	Kind error
+	// Err is the underlying error, e.g. the transport error or the provider specific error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s error %s (status %d): %s", e.Provider, e.Code, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("%s error (status %d): %s", e.Provider, e.StatusCode, msg)
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewHTTPError creates error classified by the HTTP status code of the provider response.
+// The response header is used to read Retry-After, it could be nil.
+func NewHTTPError(provider string, statusCode int, header http.Header, message string) *Error {
+	platformErr := &Error{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Message:    message,
+		RetryAfter: ParseRetryAfter(header.Get("Retry-After"), time.Now()),
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		platformErr.Kind = ErrUnauthorized
+	case statusCode == http.StatusTooManyRequests:
+		platformErr.Kind = ErrRateLimited
+		platformErr.Retryable = true
+	case statusCode >= http.StatusInternalServerError || statusCode == http.StatusRequestTimeout:
+		platformErr.Kind = ErrProviderUnavailable
+		platformErr.Retryable = true
+	case statusCode >= http.StatusBadRequest:
+		platformErr.Kind = ErrBadRequest
+	}
+	return platformErr
+}
+
+// NewTransportError creates error of a failed connection to the provider, which is always retryable.
+func NewTransportError(provider string, err error) *Error {
+	return &Error{
+		Provider:  provider,
+		Kind:      ErrProviderUnavailable,
+		Retryable: true,
+		Err:       err,
+	}
+}
+
+// NewSMTPError creates error classified by the SMTP reply code, see RFC 5321 section 4.2.
+// Errors other than *textproto.Error are considered transport errors.
+func NewSMTPError(provider string, err error) *Error {
+	var protocolErr *textproto.Error
+	if !errors.As(err, &protocolErr) {
+		return NewTransportError(provider, err)
+	}
+
+	platformErr := &Error{
+		Provider:   provider,
+		StatusCode: protocolErr.Code,
+		Message:    protocolErr.Msg,
+		Err:        err,
+	}
+	switch {
+	case protocolErr.Code == 535 || protocolErr.Code == 530:
+		platformErr.Kind = ErrUnauthorized
+	case protocolErr.Code == 421 || protocolErr.Code == 450 || protocolErr.Code == 451:
+		platformErr.Kind = ErrProviderUnavailable
+		platformErr.Retryable = true
+	case protocolErr.Code == 452:
+		platformErr.Kind = ErrRateLimited
+		platformErr.Retryable = true
+	case protocolErr.Code >= 400 && protocolErr.Code < 500:
+		platformErr.Retryable = true
+	case protocolErr.Code >= 500:
+		platformErr.Kind = ErrBadRequest
+	}
+	return platformErr
+}
+
+// ParseRetryAfter parses Retry-After header value, either delay in seconds or HTTP date.
+// It returns 0 if the value is empty or not valid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// GetRetryAfter returns the delay requested by the provider before retrying the error, or 0 if not requested.
+func GetRetryAfter(err error) time.Duration {
+	var platformErr *Error
+	if errors.As(err, &platformErr) {
+		return platformErr.RetryAfter
+	}
+	return 0
+}
+
+// IsRetryable tells whether the error is classified as retryable by the sender platform.
+func IsRetryable(err error) bool {
+	var platformErr *Error
+	if errors.As(err, &platformErr) {
+		return platformErr.Retryable
+	}
+	return false
+}
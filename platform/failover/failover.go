@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+// Platform is a sender platform of the failover chain.
+type Platform struct {
+	// ID is the sender platform id, used as the result provider if the platform doesn't report it.
+	ID             string
+	SenderPlatform platform.SenderPlatform
+}
+
+// SenderPlatform sends through the first platform of the chain, and falls through to the next one
+// when the current platform returns a retryable or unavailable error.
+type SenderPlatform struct {
+	Platforms []Platform
+}
+
+func NewFailoverSenderPlatform(platforms ...Platform) platform.SenderPlatform {
+	return &SenderPlatform{
+		Platforms: platforms,
+	}
+}
+
+func (e SenderPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e SenderPlatform) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	return e.failover(ctx, emailData.To, emailData.IsScheduled(), func(p Platform) (*platform.SendResult, error) {
+		return platform.SendWithResult(ctx, p.SenderPlatform, p.ID, emailData)
+	})
+}
+
+// MaxBatchSize returns the smallest max batch size of the platforms, 0 if any of them doesn't support batch.
+func (e SenderPlatform) MaxBatchSize() int {
+	maxBatchSize := 0
+	for i, p := range e.Platforms {
+		size := platform.MaxBatchSize(p.SenderPlatform)
+		if size == 0 {
+			return 0
+		}
+		if i == 0 || size < maxBatchSize {
+			maxBatchSize = size
+		}
+	}
+	return maxBatchSize
+}
+
+// CanSendTemplate returns true if all the platforms send the provider templates, as the email could fail over to any of them.
+func (e SenderPlatform) CanSendTemplate() bool {
+	for _, p := range e.Platforms {
+		if !platform.CanSendTemplate(p.SenderPlatform) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e SenderPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	recipientsDescription := fmt.Sprintf("%d recipients", len(recipients))
+	return e.failover(ctx, recipientsDescription, emailData.IsScheduled(), func(p Platform) (*platform.SendResult, error) {
+		return platform.SendBatchChunk(ctx, p.SenderPlatform, p.ID, emailData, recipients)
+	})
+}
+
+// CanSchedule returns true if the first platform schedules the email natively, the scheduled email doesn't fail over.
+func (e SenderPlatform) CanSchedule(sendAt time.Time) bool {
+	if len(e.Platforms) == 0 {
+		return false
+	}
+	return platform.CanSchedule(e.Platforms[0].SenderPlatform, sendAt)
+}
+
+func (e SenderPlatform) CancelScheduled(ctx context.Context, scheduleID string) error {
+	if len(e.Platforms) == 0 {
+		return platform.ErrSchedulingNotSupported
+	}
+	return platform.CancelScheduled(ctx, e.Platforms[0].SenderPlatform, scheduleID)
+}
+
+// failover sends through the platforms in order until one of them succeeds.
+// The scheduled email is only sent through the first platform, the next ones would send it immediately
+// or schedule it where it couldn't be cancelled.
+func (e SenderPlatform) failover(ctx context.Context, to string, scheduled bool, send func(p Platform) (*platform.SendResult, error)) (*platform.SendResult, error) {
+	if len(e.Platforms) == 0 {
+		return nil, errors.New("failover sender platform has no platform")
+	}
+
+	var err error
+	for i, p := range e.Platforms {
+		var result *platform.SendResult
+		result, err = send(p)
+		if err == nil {
+			return result, nil
+		}
+		if scheduled || !shouldFailover(err) || ctx.Err() != nil {
+			return result, err
+		}
+		if i < len(e.Platforms)-1 {
+			logrus.Warnf("Fail send email to %s using %s, failing over to %s: %s", to, p.ID, e.Platforms[i+1].ID, err)
+		}
+	}
+	return nil, err
+}
+
+func shouldFailover(err error) bool {
+	return platform.IsRetryable(err) || errors.Is(err, platform.ErrProviderUnavailable)
+}
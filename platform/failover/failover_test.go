@@ -0,0 +1,294 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// fakePlatform returns the errors in order, then succeeds.
+type fakePlatform struct {
+	errs        []error
+	calls       int
+	canSchedule bool
+	cancelled   []string
+}
+
+func (p *fakePlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return p.errs[p.calls-1]
+	}
+	return nil
+}
+
+func (p *fakePlatform) CanSchedule(sendAt time.Time) bool {
+	return p.canSchedule
+}
+
+func (p *fakePlatform) CancelScheduled(ctx context.Context, scheduleID string) error {
+	p.cancelled = append(p.cancelled, scheduleID)
+	return nil
+}
+
+func TestSenderPlatform_Scheduled(t *testing.T) {
+	unavailableErr := &platform.Error{Provider: "first", Kind: platform.ErrProviderUnavailable, Retryable: true}
+	first := &fakePlatform{errs: []error{unavailableErr}, canSchedule: true}
+	second := &fakePlatform{}
+	senderPlatform := NewFailoverSenderPlatform(Platform{ID: "first", SenderPlatform: first}, Platform{ID: "second", SenderPlatform: second})
+
+	sendAt := time.Now().Add(time.Hour)
+	if !platform.CanSchedule(senderPlatform, sendAt) {
+		t.Error("expected scheduled by the first platform")
+	}
+	_, err := platform.SendWithResult(context.Background(), senderPlatform, "failover", object.EmailData{To: "player@example.com", SendAt: sendAt})
+	if !errors.Is(err, platform.ErrProviderUnavailable) {
+		t.Errorf("expected the first platform error, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("expected the scheduled email not failed over, sent %d times", second.calls)
+	}
+
+	if err = platform.CancelScheduled(context.Background(), senderPlatform, "schedule-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.cancelled) != 1 || first.cancelled[0] != "schedule-1" {
+		t.Errorf("expected cancelled by the first platform, got %v", first.cancelled)
+	}
+
+	first.canSchedule = false
+	if platform.CanSchedule(senderPlatform, sendAt) {
+		t.Error("expected not scheduled if the first platform can't schedule")
+	}
+}
+
+// batchPlatform sends the batch with the errors of fakePlatform, recording the recipients of each request.
+type batchPlatform struct {
+	*fakePlatform
+	maxBatchSize int
+	batches      [][]object.Recipient
+}
+
+func (p *batchPlatform) MaxBatchSize() int {
+	return p.maxBatchSize
+}
+
+func (p *batchPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	p.batches = append(p.batches, recipients)
+	if err := p.Send(ctx, emailData); err != nil {
+		return nil, err
+	}
+	return platform.NewSendResult("").Complete(emailData), nil
+}
+
+type templatePlatform struct {
+	fakePlatform
+	canSendTemplate bool
+}
+
+func (p *templatePlatform) CanSendTemplate() bool {
+	return p.canSendTemplate
+}
+
+func TestSenderPlatform_SendWithResult(t *testing.T) {
+	unavailableErr := &platform.Error{Provider: "first", Kind: platform.ErrProviderUnavailable}
+	rateLimitedErr := &platform.Error{Provider: "first", Kind: platform.ErrRateLimited, Retryable: true}
+	badRequestErr := &platform.Error{Provider: "first", Kind: platform.ErrBadRequest}
+	unclassifiedErr := errors.New("unexpected error")
+
+	testCases := []struct {
+		name             string
+		errs             [][]error
+		cancelCtx        bool
+		expectedErr      error
+		expectedProvider string
+		expectedCalls    []int
+	}{
+		{
+			name:             "first succeeds",
+			errs:             [][]error{nil, nil},
+			expectedProvider: "first",
+			expectedCalls:    []int{1, 0},
+		},
+		{
+			name:             "fails over on unavailable",
+			errs:             [][]error{{unavailableErr}, nil},
+			expectedProvider: "second",
+			expectedCalls:    []int{1, 1},
+		},
+		{
+			name:             "fails over on retryable",
+			errs:             [][]error{{rateLimitedErr}, nil},
+			expectedProvider: "second",
+			expectedCalls:    []int{1, 1},
+		},
+		{
+			name:             "fails over through the chain",
+			errs:             [][]error{{unavailableErr}, {rateLimitedErr}, nil},
+			expectedProvider: "third",
+			expectedCalls:    []int{1, 1, 1},
+		},
+		{
+			name:          "doesn't fail over on bad request",
+			errs:          [][]error{{badRequestErr}, nil},
+			expectedErr:   badRequestErr,
+			expectedCalls: []int{1, 0},
+		},
+		{
+			name:          "doesn't fail over on unclassified error",
+			errs:          [][]error{{unclassifiedErr}, nil},
+			expectedErr:   unclassifiedErr,
+			expectedCalls: []int{1, 0},
+		},
+		{
+			name:          "returns the last error if all fail",
+			errs:          [][]error{{unavailableErr}, {rateLimitedErr}},
+			expectedErr:   rateLimitedErr,
+			expectedCalls: []int{1, 1},
+		},
+		{
+			name:          "stops on the last non retryable error",
+			errs:          [][]error{{unavailableErr}, {badRequestErr}, nil},
+			expectedErr:   badRequestErr,
+			expectedCalls: []int{1, 1, 0},
+		},
+		{
+			name:          "doesn't fail over if the context is done",
+			errs:          [][]error{{unavailableErr}, nil},
+			cancelCtx:     true,
+			expectedErr:   unavailableErr,
+			expectedCalls: []int{1, 0},
+		},
+	}
+
+	ids := []string{"first", "second", "third"}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fakePlatforms := make([]*fakePlatform, len(testCase.errs))
+			platforms := make([]Platform, len(testCase.errs))
+			for i, errs := range testCase.errs {
+				fakePlatforms[i] = &fakePlatform{errs: errs}
+				platforms[i] = Platform{ID: ids[i], SenderPlatform: fakePlatforms[i]}
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testCase.cancelCtx {
+				cancel()
+			}
+
+			result, err := NewFailoverSenderPlatform(platforms...).(*SenderPlatform).SendWithResult(ctx, object.EmailData{To: "player@example.com"})
+			if err != testCase.expectedErr {
+				t.Errorf("expected error %v, got %v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr == nil && (result == nil || result.Provider != testCase.expectedProvider) {
+				t.Errorf("expected result of %s, got %+v", testCase.expectedProvider, result)
+			}
+			for i, expectedCalls := range testCase.expectedCalls {
+				if fakePlatforms[i].calls != expectedCalls {
+					t.Errorf("expected %s called %d times, got %d", ids[i], expectedCalls, fakePlatforms[i].calls)
+				}
+			}
+		})
+	}
+}
+
+func TestSenderPlatform_NoPlatform(t *testing.T) {
+	senderPlatform := NewFailoverSenderPlatform()
+	if err := senderPlatform.Send(context.Background(), object.EmailData{To: "player@example.com"}); err == nil {
+		t.Error("expected error of the empty chain")
+	}
+	if platform.CanSchedule(senderPlatform, time.Now().Add(time.Hour)) {
+		t.Error("expected the empty chain not scheduling")
+	}
+	if err := platform.CancelScheduled(context.Background(), senderPlatform, "schedule-1"); !errors.Is(err, platform.ErrSchedulingNotSupported) {
+		t.Errorf("expected ErrSchedulingNotSupported, got %v", err)
+	}
+}
+
+func TestSenderPlatform_MaxBatchSize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		platforms    []platform.SenderPlatform
+		expectedSize int
+	}{
+		{
+			name:         "smallest size",
+			platforms:    []platform.SenderPlatform{&batchPlatform{fakePlatform: &fakePlatform{}, maxBatchSize: 1000}, &batchPlatform{fakePlatform: &fakePlatform{}, maxBatchSize: 500}},
+			expectedSize: 500,
+		},
+		{
+			name:         "any platform without batch",
+			platforms:    []platform.SenderPlatform{&batchPlatform{fakePlatform: &fakePlatform{}, maxBatchSize: 1000}, &fakePlatform{}},
+			expectedSize: 0,
+		},
+		{
+			name:         "no platform",
+			expectedSize: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			platforms := make([]Platform, len(testCase.platforms))
+			for i, senderPlatform := range testCase.platforms {
+				platforms[i] = Platform{ID: "platform", SenderPlatform: senderPlatform}
+			}
+			if size := platform.MaxBatchSize(NewFailoverSenderPlatform(platforms...)); size != testCase.expectedSize {
+				t.Errorf("expected max batch size %d, got %d", testCase.expectedSize, size)
+			}
+		})
+	}
+}
+
+func TestSenderPlatform_SendBatch(t *testing.T) {
+	unavailableErr := &platform.Error{Provider: "first", Kind: platform.ErrProviderUnavailable}
+	first := &batchPlatform{fakePlatform: &fakePlatform{errs: []error{unavailableErr}}, maxBatchSize: 2}
+	second := &batchPlatform{fakePlatform: &fakePlatform{}, maxBatchSize: 2}
+	senderPlatform := NewFailoverSenderPlatform(Platform{ID: "first", SenderPlatform: first}, Platform{ID: "second", SenderPlatform: second})
+
+	recipients := []object.Recipient{{Address: mail.Address{Address: "a@example.com"}}, {Address: mail.Address{Address: "b@example.com"}}}
+	results := platform.SendBatch(context.Background(), senderPlatform, "failover", object.EmailData{}, recipients)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected a successful chunk, got %+v", results)
+	}
+	if results[0].Result.Provider != "second" {
+		t.Errorf("expected sent by second, got %s", results[0].Result.Provider)
+	}
+	if len(first.batches) != 1 || len(second.batches) != 1 || len(second.batches[0]) != 2 {
+		t.Errorf("expected the chunk failed over, got %d and %d requests", len(first.batches), len(second.batches))
+	}
+}
+
+func TestSenderPlatform_CanSendTemplate(t *testing.T) {
+	withTemplate := Platform{ID: "mandrill", SenderPlatform: &templatePlatform{canSendTemplate: true}}
+	withoutTemplate := Platform{ID: "smtp", SenderPlatform: &templatePlatform{}}
+	assumedTemplate := Platform{ID: "inhouse", SenderPlatform: &fakePlatform{}}
+
+	if !platform.CanSendTemplate(NewFailoverSenderPlatform(withTemplate, assumedTemplate)) {
+		t.Error("expected templates supported by all the platforms")
+	}
+	if platform.CanSendTemplate(NewFailoverSenderPlatform(withTemplate, withoutTemplate)) {
+		t.Error("expected templates not supported if the email could fail over to smtp")
+	}
+}
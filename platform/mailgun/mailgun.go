@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mailgun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PlatformID = "mailgun"
+
+	ConfigKeyAPIKey = "api_key"
+	ConfigKeyDomain = "domain"
+	ConfigKeyRegion = "region"
+	ConfigKeyAPIURL = "api_url"
+
+	RegionUS = "us"
+	RegionEU = "eu"
+
+	apiHostUS     = "https://api.mailgun.net"
+	apiHostEU     = "https://api.eu.mailgun.net"
+	sendEmailPath = "/v3/%s/messages"
+)
+
+func init() {
+	platform.Register(PlatformID, NewMailgunClientFromConfig)
+}
+
+type sendResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+type MailSender struct {
+	Host   string
+	Domain string
+	APIKey string
+}
+
+// GetAPIHost returns Mailgun base URL of the given region. Unknown region falls back to US.
+func GetAPIHost(region string) string {
+	if strings.EqualFold(region, RegionEU) {
+		return apiHostEU
+	}
+	return apiHostUS
+}
+
+func NewMailgunClient(apiURL, domain, apiKey string) platform.SenderPlatform {
+	return &MailSender{
+		Host:   apiURL,
+		Domain: domain,
+		APIKey: apiKey,
+	}
+}
+
+// NewMailgunClientFromConfig creates Mailgun sender platform from the generic platform configuration.
+func NewMailgunClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	apiKey, err := config.GetRequired(ConfigKeyAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := config.GetRequired(ConfigKeyDomain)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := GetAPIHost(config.Get(ConfigKeyRegion))
+	if str := config.Get(ConfigKeyAPIURL); str != "" {
+		apiURL = str
+	}
+	return NewMailgunClient(apiURL, domain, apiKey), nil
+}
+
+func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	from := mail.Address{Address: emailData.From, Name: emailData.FromName}
+	fields := [][2]string{
+		{"from", from.String()},
+		{"subject", emailData.Subject},
+	}
+	for _, toAddress := range emailData.GetToAddresses() {
+		fields = append(fields, [2]string{"to", object.FormatAddress(toAddress)})
+	}
+	for _, bccAddress := range emailData.Bcc {
+		fields = append(fields, [2]string{"bcc", object.FormatAddress(bccAddress)})
+	}
+	for _, cc := range emailData.CarbonCopy {
+		fields = append(fields, [2]string{"cc", cc})
+	}
+	if emailData.ReplyTo != "" {
+		fields = append(fields, [2]string{"h:Reply-To", emailData.ReplyTo})
+	}
+	for _, category := range emailData.Categories {
+		fields = append(fields, [2]string{"o:tag", category})
+	}
+	if emailData.XMCTemplate != "" {
+		variables, err := json.Marshal(emailData.XMCMergeVars)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields,
+			[2]string{"template", emailData.XMCTemplate},
+			[2]string{"h:X-Mailgun-Variables", string(variables)},
+		)
+	} else {
+		if emailData.TextBody != "" {
+			fields = append(fields, [2]string{"text", emailData.TextBody})
+		}
+		if emailData.HTMLBody != "" {
+			fields = append(fields, [2]string{"html", emailData.HTMLBody})
+		}
+	}
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range emailData.Attachments {
+		if err := writeAttachment(writer, &emailData.Attachments[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+fmt.Sprintf(sendEmailPath, e.Domain), body)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using mailgun: %s", emailData.To, err)
+		return nil, err
+	}
+	req.SetBasicAuth("api", e.APIKey)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	httpClient := &http.Client{
+		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
+	}
+	result := platform.NewSendResult(PlatformID)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using mailgun: %s", emailData.To, err)
+		return nil, platform.NewTransportError(PlatformID, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		errorsResponseBody, errReadResp := ioutil.ReadAll(resp.Body)
+		if errReadResp != nil {
+			return nil, errReadResp
+		}
+		logrus.Errorf("Error send email to %s using mailgun: %s", emailData.To, string(errorsResponseBody))
+		platformErr := platform.NewHTTPError(PlatformID, resp.StatusCode, resp.Header, string(errorsResponseBody))
+		response := &sendResponse{}
+		if errUnmarshal := json.Unmarshal(errorsResponseBody, response); errUnmarshal == nil && response.Message != "" {
+			platformErr.Message = response.Message
+		}
+		return nil, platformErr
+	}
+
+	response := &sendResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		logrus.Warnf("Unable to read mailgun message id of email to %s: %s", emailData.To, err)
+	}
+	result.MessageID = response.ID
+	return result.Complete(emailData), nil
+}
+
+func writeAttachment(writer *multipart.Writer, file *object.Attachment) error {
+	content, err := file.GetContent()
+	if err != nil {
+		return err
+	}
+	// Mailgun references inline images by filename, e.g. <img src="cid:filename">
+	fieldName, filename := "attachment", file.Filename
+	if file.IsInline() {
+		fieldName = "inline"
+		if file.ContentID != "" {
+			filename = file.ContentID
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, escapeQuotes(filename)))
+	header.Set("Content-Type", file.GetContentType())
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	return err
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
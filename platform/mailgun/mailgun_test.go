@@ -0,0 +1,334 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mailgun
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+func TestMailSender_SendWithResult_Payload(t *testing.T) {
+	testCases := []struct {
+		name           string
+		emailData      object.EmailData
+		expectedFields map[string][]string
+		absentFields   []string
+	}{
+		{
+			name: "template",
+			emailData: object.EmailData{
+				From:         "noreply@mygame.com",
+				FromName:     "My Game",
+				To:           "player@example.com",
+				ToList:       []mail.Address{{Name: "Friend", Address: "friend@example.com"}},
+				Bcc:          []mail.Address{{Address: "audit@mygame.com"}},
+				CarbonCopy:   []string{"support@mygame.com"},
+				ReplyTo:      "support@mygame.com",
+				Subject:      "Verify",
+				Categories:   []string{"verify", "account"},
+				XMCTemplate:  "verify",
+				XMCMergeVars: map[string]interface{}{"code": "123456"},
+				HTMLBody:     "<p>ignored</p>",
+			},
+			expectedFields: map[string][]string{
+				"from":                  {`"My Game" <noreply@mygame.com>`},
+				"subject":               {"Verify"},
+				"to":                    {"player@example.com", `"Friend" <friend@example.com>`},
+				"bcc":                   {"audit@mygame.com"},
+				"cc":                    {"support@mygame.com"},
+				"h:Reply-To":            {"support@mygame.com"},
+				"o:tag":                 {"verify", "account"},
+				"template":              {"verify"},
+				"h:X-Mailgun-Variables": {`{"code":"123456"}`},
+			},
+			absentFields: []string{"html", "text"},
+		},
+		{
+			name: "content",
+			emailData: object.EmailData{
+				From:     "noreply@mygame.com",
+				To:       "player@example.com",
+				Subject:  "Verify",
+				TextBody: "Your code is 123456",
+				HTMLBody: "<p>Your code is 123456</p>",
+			},
+			expectedFields: map[string][]string{
+				"from":    {"<noreply@mygame.com>"},
+				"subject": {"Verify"},
+				"to":      {"player@example.com"},
+				"text":    {"Your code is 123456"},
+				"html":    {"<p>Your code is 123456</p>"},
+			},
+			absentFields: []string{"template", "h:X-Mailgun-Variables", "h:Reply-To", "cc", "bcc", "o:tag"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var request *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Error(err)
+				}
+				request = r
+				_, _ = w.Write([]byte(`{"id":"<20230101.1@mg.mygame.com>","message":"Queued. Thank you."}`))
+			}))
+			defer server.Close()
+
+			sender := NewMailgunClient(server.URL, "mg.mygame.com", "key-123").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), testCase.emailData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.MessageID != "<20230101.1@mg.mygame.com>" {
+				t.Errorf("expected message id from the response, got %q", result.MessageID)
+			}
+			if result.Provider != PlatformID {
+				t.Errorf("expected provider %s, got %s", PlatformID, result.Provider)
+			}
+
+			if request.Method != http.MethodPost || request.URL.Path != "/v3/mg.mygame.com/messages" {
+				t.Errorf("unexpected request %s %s", request.Method, request.URL.Path)
+			}
+			if username, password, ok := request.BasicAuth(); !ok || username != "api" || password != "key-123" {
+				t.Errorf("unexpected basic auth %s:%s", username, password)
+			}
+			for field, expected := range testCase.expectedFields {
+				if actual := request.MultipartForm.Value[field]; !reflect.DeepEqual(actual, expected) {
+					t.Errorf("expected field %s %v, got %v", field, expected, actual)
+				}
+			}
+			for _, field := range testCase.absentFields {
+				if actual, exists := request.MultipartForm.Value[field]; exists {
+					t.Errorf("expected no field %s, got %v", field, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestMailSender_SendWithResult_Attachments(t *testing.T) {
+	type part struct {
+		filename    string
+		contentType string
+		content     string
+	}
+	parts := map[string][]part{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+		}
+		for fieldName, files := range r.MultipartForm.File {
+			for _, file := range files {
+				reader, err := file.Open()
+				if err != nil {
+					t.Error(err)
+					continue
+				}
+				content, _ := ioutil.ReadAll(reader)
+				_ = reader.Close()
+				parts[fieldName] = append(parts[fieldName], part{file.Filename, file.Header.Get("Content-Type"), string(content)})
+			}
+		}
+		_, _ = w.Write([]byte(`{"id":"<1@mg.mygame.com>"}`))
+	}))
+	defer server.Close()
+
+	sender := NewMailgunClient(server.URL, "mg.mygame.com", "key-123").(*MailSender)
+	_, err := sender.SendWithResult(context.Background(), object.EmailData{
+		From:     "noreply@mygame.com",
+		To:       "player@example.com",
+		Subject:  "Receipt",
+		HTMLBody: `<img src="cid:logo.png">`,
+		Attachments: []object.Attachment{
+			{Filename: "receipt.pdf", ContentType: "application/pdf", Reader: strings.NewReader("%PDF")},
+			{Filename: "image.png", ContentType: "image/png", Content: []byte("png"), Disposition: object.AttachmentDispositionInline, ContentID: "logo.png"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]part{
+		"attachment": {{"receipt.pdf", "application/pdf", "%PDF"}},
+		"inline":     {{"logo.png", "image/png", "png"}},
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("expected parts %v, got %v", expected, parts)
+	}
+}
+
+func TestMailSender_SendWithResult_Error(t *testing.T) {
+	testCases := []struct {
+		name              string
+		statusCode        int
+		header            map[string]string
+		body              string
+		expectedKind      error
+		expectedRetryable bool
+		expectedMessage   string
+		expectedRetry     time.Duration
+	}{
+		{
+			name:            "unauthorized",
+			statusCode:      http.StatusUnauthorized,
+			body:            "Forbidden",
+			expectedKind:    platform.ErrUnauthorized,
+			expectedMessage: "Forbidden",
+		},
+		{
+			name:            "bad request",
+			statusCode:      http.StatusBadRequest,
+			body:            `{"message":"to parameter is not a valid address. please check documentation"}`,
+			expectedKind:    platform.ErrBadRequest,
+			expectedMessage: "to parameter is not a valid address. please check documentation",
+		},
+		{
+			name:              "rate limited",
+			statusCode:        http.StatusTooManyRequests,
+			header:            map[string]string{"Retry-After": "30"},
+			body:              `{"message":"Too many requests"}`,
+			expectedKind:      platform.ErrRateLimited,
+			expectedRetryable: true,
+			expectedMessage:   "Too many requests",
+			expectedRetry:     30 * time.Second,
+		},
+		{
+			name:              "unavailable",
+			statusCode:        http.StatusServiceUnavailable,
+			body:              "Service Unavailable",
+			expectedKind:      platform.ErrProviderUnavailable,
+			expectedRetryable: true,
+			expectedMessage:   "Service Unavailable",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range testCase.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(testCase.statusCode)
+				_, _ = w.Write([]byte(testCase.body))
+			}))
+			defer server.Close()
+
+			sender := NewMailgunClient(server.URL, "mg.mygame.com", "key-123").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), object.EmailData{
+				From:     "noreply@mygame.com",
+				To:       "player@example.com",
+				Subject:  "Verify",
+				TextBody: "Your code is 123456",
+			})
+			if result != nil {
+				t.Errorf("expected no result, got %+v", result)
+			}
+
+			var platformErr *platform.Error
+			if !errors.As(err, &platformErr) {
+				t.Fatalf("expected *platform.Error, got %v", err)
+			}
+			if !errors.Is(err, testCase.expectedKind) {
+				t.Errorf("expected kind %v, got %v", testCase.expectedKind, platformErr.Kind)
+			}
+			if platformErr.Provider != PlatformID || platformErr.StatusCode != testCase.statusCode {
+				t.Errorf("unexpected provider %s or status code %d", platformErr.Provider, platformErr.StatusCode)
+			}
+			if platformErr.Retryable != testCase.expectedRetryable {
+				t.Errorf("expected retryable %v", testCase.expectedRetryable)
+			}
+			if platformErr.Message != testCase.expectedMessage {
+				t.Errorf("expected message %q, got %q", testCase.expectedMessage, platformErr.Message)
+			}
+			if platformErr.RetryAfter != testCase.expectedRetry {
+				t.Errorf("expected retry after %s, got %s", testCase.expectedRetry, platformErr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestMailSender_SendWithResult_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	serverURL := server.URL
+	server.Close()
+
+	sender := NewMailgunClient(serverURL, "mg.mygame.com", "key-123").(*MailSender)
+	_, err := sender.SendWithResult(context.Background(), object.EmailData{From: "noreply@mygame.com", To: "player@example.com"})
+	if !errors.Is(err, platform.ErrProviderUnavailable) || !platform.IsRetryable(err) {
+		t.Errorf("expected retryable ErrProviderUnavailable, got %v", err)
+	}
+}
+
+func TestNewMailgunClientFromConfig(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       platform.Config
+		expectedHost string
+		expectErr    bool
+	}{
+		{
+			name:         "default region",
+			config:       platform.Config{ConfigKeyAPIKey: "key-123", ConfigKeyDomain: "mg.mygame.com"},
+			expectedHost: apiHostUS,
+		},
+		{
+			name:         "eu region",
+			config:       platform.Config{ConfigKeyAPIKey: "key-123", ConfigKeyDomain: "mg.mygame.com", ConfigKeyRegion: "EU"},
+			expectedHost: apiHostEU,
+		},
+		{
+			name:         "api url overrides region",
+			config:       platform.Config{ConfigKeyAPIKey: "key-123", ConfigKeyDomain: "mg.mygame.com", ConfigKeyRegion: RegionEU, ConfigKeyAPIURL: "http://localhost:8080"},
+			expectedHost: "http://localhost:8080",
+		},
+		{
+			name:      "missing domain",
+			config:    platform.Config{ConfigKeyAPIKey: "key-123"},
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			senderPlatform, err := NewMailgunClientFromConfig(testCase.config)
+			if testCase.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if host := senderPlatform.(*MailSender).Host; host != testCase.expectedHost {
+				t.Errorf("expected host %s, got %s", testCase.expectedHost, host)
+			}
+		})
+	}
+}
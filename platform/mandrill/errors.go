@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mandrill
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+type errorResponse struct {
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// newError parses Mandrill {status,code,name,message} response into platform error.
+// The errors not known to be transient are not retryable.
+func newError(resp *http.Response, responseBody []byte) *platform.Error {
+	platformErr := platform.NewHTTPError(PlatformID, resp.StatusCode, resp.Header, string(responseBody))
+
+	response := &errorResponse{}
+	if err := json.Unmarshal(responseBody, response); err != nil || response.Name == "" {
+		return platformErr
+	}
+	platformErr.Code = response.Name
+	platformErr.Message = response.Message
+
+	switch response.Name {
+	case "Invalid_Key", "PaymentRequired":
+		platformErr.Kind = platform.ErrUnauthorized
+		platformErr.Retryable = false
+	case "ValidationError", "Unknown_Template", "Unknown_Subaccount", "Invalid_Template":
+		platformErr.Kind = platform.ErrBadRequest
+		platformErr.Retryable = false
+	case "GeneralError", "ServiceUnavailable":
+		platformErr.Kind = platform.ErrProviderUnavailable
+		platformErr.Retryable = true
+	default:
+		// Mandrill responds with HTTP 500 to every API error, so the other named errors are client errors, e.g. Unknown_Sender
+		platformErr.Kind = platform.ErrBadRequest
+		platformErr.Retryable = false
+	}
+	return platformErr
+}
@@ -16,7 +16,26 @@
 
 package mandrill
 
-const PlatformID = "mandrill"
+import "github.com/AccelByte/justice-go-common-email/platform"
+
+const (
+	PlatformID = "mandrill"
+
+	ConfigKeyAPIURL   = "api_url"
+	ConfigKeyAPIKey   = "api_key"
+	ConfigKeySMTPHost = "smtp_host"
+	ConfigKeySMTPPort = "smtp_port"
+	ConfigKeyUsername = "username"
+	ConfigKeyPassword = "password"
+
+	defaultAPIURL   = "https://mandrillapp.com"
+	defaultSMTPHost = "smtp.mandrillapp.com"
+	defaultSMTPPort = 587
+)
+
+func init() {
+	platform.Register(PlatformID, NewMandrillClientFromConfig)
+}
 
 type MailSender struct {
 	Host   string
@@ -29,3 +48,25 @@ type SMTPMailSender struct {
 	Username string
 	Password string
 }
+
+// NewMandrillClientFromConfig creates Mandrill sender platform from the generic platform configuration.
+// The API mode is used if api_key is set, otherwise the SMTP mode is used.
+func NewMandrillClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	apiURL := defaultAPIURL
+	smtpHost := defaultSMTPHost
+	if str := config.Get(ConfigKeyAPIURL); str != "" {
+		apiURL = str
+	}
+	if str := config.Get(ConfigKeySMTPHost); str != "" {
+		smtpHost = str
+	}
+	smtpPort, err := config.GetInt(ConfigKeySMTPPort, defaultSMTPPort)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey := config.Get(ConfigKeyAPIKey); apiKey != "" {
+		return NewMandrillClientWithAPIKey(apiURL, apiKey), nil
+	}
+	return NewMandrillClientWithSMTP(smtpHost, smtpPort, config.Get(ConfigKeyUsername), config.Get(ConfigKeyPassword)), nil
+}
@@ -19,11 +19,12 @@ package mandrill
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/AccelByte/justice-go-common-email/constant"
@@ -32,7 +33,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const sendEmailPath = "/api/1.0/messages/send-template.json"
+const (
+	sendEmailPath         = "/api/1.0/messages/send.json"
+	sendTemplateEmailPath = "/api/1.0/messages/send-template.json"
+	cancelScheduledPath   = "/api/1.0/messages/cancel-scheduled.json"
+	sendAtFormat          = "2006-01-02 15:04:05"
+
+	// MaxBatchRecipients is the max recipients of a batch request.
+	MaxBatchRecipients = 1000
+
+	recipientStatusRejected = "rejected"
+	recipientStatusInvalid  = "invalid"
+)
 
 type mailTo struct {
 	Email string `json:"email"`
@@ -51,21 +63,51 @@ type mergeVar struct {
 	Content string `json:"content"`
 }
 
+type recipientMergeVars struct {
+	Rcpt string     `json:"rcpt"`
+	Vars []mergeVar `json:"vars"`
+}
+
 type message struct {
-	Subject         string       `json:"subject"`
-	FromEmail       string       `json:"from_email"`
-	FromName        string       `json:"from_name"`
-	To              []mailTo     `json:"to"`
-	GlobalMergeVars []mergeVar   `json:"global_merge_vars"`
-	Attachment      []attachment `json:"attachments"`
+	Subject            string               `json:"subject"`
+	HTML               string               `json:"html,omitempty"`
+	Text               string               `json:"text,omitempty"`
+	FromEmail          string               `json:"from_email"`
+	FromName           string               `json:"from_name"`
+	To                 []mailTo             `json:"to"`
+	Headers            map[string]string    `json:"headers,omitempty"`
+	PreserveRecipients *bool                `json:"preserve_recipients,omitempty"`
+	Tags               []string             `json:"tags,omitempty"`
+	GlobalMergeVars    []mergeVar           `json:"global_merge_vars"`
+	MergeVars          []recipientMergeVars `json:"merge_vars,omitempty"`
+	Attachment         []attachment         `json:"attachments"`
+	Images             []attachment         `json:"images,omitempty"`
+	Metadata           map[string]string    `json:"metadata,omitempty"`
+}
+
+type sendResult struct {
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+	RejectReason string `json:"reject_reason"`
+	ID           string `json:"_id"`
 }
 
 type emailPayload struct {
-	Key             string  `json:"key"`
-	TemplateName    string  `json:"template_name"`
-	TemplateContent string  `json:"template_content"`
-	Message         message `json:"message"`
-	Async           bool    `json:"async"`
+	Key     string  `json:"key"`
+	Message message `json:"message"`
+	Async   bool    `json:"async"`
+	SendAt  string  `json:"send_at,omitempty"`
+}
+
+type templateEmailPayload struct {
+	emailPayload
+	TemplateName    string `json:"template_name"`
+	TemplateContent string `json:"template_content"`
+}
+
+type cancelScheduledPayload struct {
+	Key string `json:"key"`
+	ID  string `json:"id"`
 }
 
 func NewMandrillClientWithAPIKey(apiURL, apiKey string) platform.SenderPlatform {
@@ -76,64 +118,262 @@ func NewMandrillClientWithAPIKey(apiURL, apiKey string) platform.SenderPlatform
 }
 
 func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
-	mergeVars := convertToMergeVars(emailData.XMCMergeVars)
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	msg, err := newMessage(emailData)
+	if err != nil {
+		return nil, err
+	}
+	msg.To = convertToMailTo(emailData)
+	msg.GlobalMergeVars = convertToMergeVars(emailData.XMCMergeVars)
+	if len(emailData.CarbonCopy) > 0 {
+		// without preserve_recipients, each recipient receives its own copy and the cc would not be visible
+		preserveRecipients := true
+		msg.PreserveRecipients = &preserveRecipients
+	}
+
+	// the result is returned along with the error if some recipients are rejected, so the accepted ones are known
+	result, err := e.send(ctx, emailData, msg)
+	if result != nil {
+		result.Complete(emailData)
+	}
+	return result, err
+}
+
+// MaxBatchSize returns the max recipients of a request.
+func (e MailSender) MaxBatchSize() int {
+	return MaxBatchRecipients
+}
+
+// SendBatch sends the email to the recipients in a request, each recipient receives its own copy with its own merge vars.
+// The rejected recipients are returned in both the result and the error.
+func (e MailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	msg, err := newMessage(emailData)
+	if err != nil {
+		return nil, err
+	}
+	msg.To = make([]mailTo, 0, len(recipients))
+	// set explicitly instead of the account setting, so the recipients never see each other
+	preserveRecipients := false
+	msg.PreserveRecipients = &preserveRecipients
+	msg.GlobalMergeVars = convertToMergeVars(emailData.XMCMergeVars)
+	for _, recipient := range recipients {
+		msg.To = append(msg.To, mailTo{Email: recipient.Address.Address, Name: recipient.Address.Name, Type: "to"})
+		if len(recipient.MergeVars) > 0 {
+			msg.MergeVars = append(msg.MergeVars, recipientMergeVars{
+				Rcpt: recipient.Address.Address,
+				Vars: convertToMergeVars(recipient.MergeVars),
+			})
+		}
+	}
+
+	result, err := e.send(ctx, emailData, msg)
+	if result != nil {
+		result.Complete(emailData)
+	}
+	return result, err
+}
+
+// newMessage creates the message of the email without the recipients and merge vars.
+func newMessage(emailData object.EmailData) (message, error) {
 	msg := message{
 		Subject:   emailData.Subject,
 		FromEmail: emailData.From,
 		FromName:  emailData.FromName,
-		To: []mailTo{
-			{
-				Email: emailData.To,
-				Type:  "to",
-			},
-		},
-		GlobalMergeVars: mergeVars,
-	}
-	payload := &emailPayload{
-		Key:          e.APIKey,
-		TemplateName: emailData.XMCTemplate,
-		Message:      msg,
-		Async:        false,
-	}
-
-	payloadBytes, err := json.Marshal(payload)
+		Tags:      emailData.Categories,
+	}
+	if emailData.XMCTemplate == "" {
+		msg.HTML = emailData.HTMLBody
+		msg.Text = emailData.TextBody
+	}
+	if emailData.ReplyTo != "" {
+		msg.Headers = map[string]string{"Reply-To": emailData.ReplyTo}
+	}
+	if emailData.Namespace != "" {
+		msg.Metadata = map[string]string{constant.NamespaceMetadataKey: emailData.Namespace}
+	}
+	for i := range emailData.Attachments {
+		content, err := emailData.Attachments[i].GetContent()
+		if err != nil {
+			return msg, err
+		}
+		file := attachment{
+			Types:   emailData.Attachments[i].GetContentType(),
+			Name:    emailData.Attachments[i].Filename,
+			Content: base64.StdEncoding.EncodeToString(content),
+		}
+		if emailData.Attachments[i].IsInline() {
+			// Mandrill references inline images by name, e.g. <img src="cid:name">
+			if emailData.Attachments[i].ContentID != "" {
+				file.Name = emailData.Attachments[i].ContentID
+			}
+			msg.Images = append(msg.Images, file)
+		} else {
+			msg.Attachment = append(msg.Attachment, file)
+		}
+	}
+	return msg, nil
+}
+
+// send sends the message, returning the result along with the error if any recipient is rejected.
+func (e MailSender) send(ctx context.Context, emailData object.EmailData, msg message) (*platform.SendResult, error) {
+	payload := emailPayload{
+		Key:     e.APIKey,
+		Message: msg,
+		Async:   false,
+	}
+	if emailData.IsScheduled() {
+		payload.SendAt = emailData.SendAt.UTC().Format(sendAtFormat)
+	}
+
+	// the email without template, e.g. rendered locally, is sent with its own content
+	path := sendEmailPath
+	var payloadBytes []byte
+	var err error
+	if emailData.XMCTemplate != "" {
+		path = sendTemplateEmailPath
+		payloadBytes, err = json.Marshal(templateEmailPayload{emailPayload: payload, TemplateName: emailData.XMCTemplate})
+	} else {
+		payloadBytes, err = json.Marshal(payload)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 	body := bytes.NewBuffer(payloadBytes)
 
 	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
 	defer cancel()
-	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+sendEmailPath, body)
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+path, body)
 	if err != nil {
 		logrus.Errorf("Error send email to %s using Mandrill API: %s", emailData.To, err)
-		return err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	httpClient := &http.Client{
 		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
 	}
+	result := platform.NewSendResult(PlatformID)
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		logrus.Errorf("Error send email to %s using Mandrill API: %s", emailData.To, err)
+		return nil, platform.NewTransportError(PlatformID, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	responseBody, errReadResp := ioutil.ReadAll(resp.Body)
+	if errReadResp != nil {
+		return nil, errReadResp
+	}
+	if resp.StatusCode != http.StatusOK {
+		logrus.Errorf("Error send email to %s using Mandrill API: %s", emailData.To, string(responseBody))
+		return nil, newError(resp, responseBody)
+	}
+
+	var results []sendResult
+	if err = json.Unmarshal(responseBody, &results); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal Mandrill API response: %v", err)
+	}
+	var rejectedRecipients, scheduledIDs []string
+	for _, recipientResult := range results {
+		if recipientResult.Status == recipientStatusRejected || recipientResult.Status == recipientStatusInvalid {
+			rejectedRecipients = append(rejectedRecipients, fmt.Sprintf("%s (%s: %s)", recipientResult.Email, recipientResult.Status, recipientResult.RejectReason))
+			result.RejectedRecipients = append(result.RejectedRecipients, recipientResult.Email)
+			continue
+		}
+		result.AcceptedRecipients = append(result.AcceptedRecipients, recipientResult.Email)
+		// Mandrill assigns an id per recipient, the first one is used as the message id
+		if result.MessageID == "" {
+			result.MessageID = recipientResult.ID
+		}
+		scheduledIDs = append(scheduledIDs, recipientResult.ID)
+	}
+	if len(rejectedRecipients) > 0 {
+		logrus.Errorf("Error send email to %s using Mandrill API: %s", emailData.To, strings.Join(rejectedRecipients, ", "))
+		return result, &platform.Error{
+			Provider:   PlatformID,
+			StatusCode: resp.StatusCode,
+			Message:    strings.Join(rejectedRecipients, ", "),
+			Recipients: result.RejectedRecipients,
+			Kind:       platform.ErrInvalidRecipient,
+		}
+	}
+	if payload.SendAt != "" {
+		// each recipient is scheduled separately, so all of them are cancelled together
+		result.ScheduleID = strings.Join(scheduledIDs, ",")
+	}
+	return result, nil
+}
+
+func (e MailSender) CanSchedule(time.Time) bool {
+	return true
+}
+
+// CancelScheduled cancels the scheduled email by the comma separated message ids of its recipients.
+func (e MailSender) CancelScheduled(ctx context.Context, scheduleID string) error {
+	for _, id := range strings.Split(scheduleID, ",") {
+		if err := e.cancelScheduledMessage(ctx, id); err != nil {
+			logrus.Errorf("Error cancel scheduled email %s using Mandrill API: %s", id, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (e MailSender) cancelScheduledMessage(ctx context.Context, id string) error {
+	payloadBytes, err := json.Marshal(&cancelScheduledPayload{Key: e.APIKey, ID: id})
+	if err != nil {
+		return err
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+cancelScheduledPath, bytes.NewReader(payloadBytes))
+	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := &http.Client{
+		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return platform.NewTransportError(PlatformID, err)
+	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		errorsResponseBody, errReadResp := ioutil.ReadAll(resp.Body)
+		responseBody, errReadResp := ioutil.ReadAll(resp.Body)
 		if errReadResp != nil {
 			return errReadResp
 		}
-		logrus.Errorf("Error send email to %s using Mandrill API: %s", emailData.To, string(errorsResponseBody))
-		return errors.New(string(errorsResponseBody))
+		return newError(resp, responseBody)
 	}
 	return nil
 }
 
+func convertToMailTo(emailData object.EmailData) []mailTo {
+	recipients := make([]mailTo, 0)
+	for _, toAddress := range emailData.GetToAddresses() {
+		recipients = append(recipients, mailTo{Email: toAddress.Address, Name: toAddress.Name, Type: "to"})
+	}
+	for _, ccAddress := range emailData.CarbonCopy {
+		recipients = append(recipients, mailTo{Email: ccAddress, Type: "cc"})
+	}
+	for _, bccAddress := range emailData.Bcc {
+		recipients = append(recipients, mailTo{Email: bccAddress.Address, Name: bccAddress.Name, Type: "bcc"})
+	}
+	return recipients
+}
+
 func convertToMergeVars(xmcMergeVars map[string]interface{}) []mergeVar {
 	mergeVars := make([]mergeVar, 0)
 	if xmcMergeVars != nil { // nolint: gosimple
@@ -0,0 +1,326 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"reflect"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// newTestServer responds to every request with the given status and body, recording the path and payload.
+func newTestServer(t *testing.T, statusCode int, body string, path *string, payload *templateEmailPayload) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path != nil {
+			*path = r.URL.Path
+		}
+		if payload != nil {
+			if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+				t.Error(err)
+			}
+		}
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestMailSender_SendWithResult_Payload(t *testing.T) {
+	preserveRecipients := true
+	testCases := []struct {
+		name            string
+		emailData       object.EmailData
+		expectedPath    string
+		expectedPayload templateEmailPayload
+	}{
+		{
+			name: "template with cc, reply-to and tags",
+			emailData: object.EmailData{
+				Namespace:    "mygame",
+				From:         "noreply@mygame.com",
+				FromName:     "My Game",
+				To:           "player@example.com",
+				ToList:       []mail.Address{{Name: "Friend", Address: "friend@example.com"}},
+				CarbonCopy:   []string{"support@mygame.com"},
+				Bcc:          []mail.Address{{Address: "audit@mygame.com"}},
+				ReplyTo:      "support@mygame.com",
+				Subject:      "Verify",
+				Categories:   []string{"verify", "account"},
+				XMCTemplate:  "verify",
+				XMCMergeVars: map[string]interface{}{"code": 123456},
+				HTMLBody:     "<p>ignored</p>",
+			},
+			expectedPath: sendTemplateEmailPath,
+			expectedPayload: templateEmailPayload{
+				emailPayload: emailPayload{
+					Key: "key-123",
+					Message: message{
+						Subject:   "Verify",
+						FromEmail: "noreply@mygame.com",
+						FromName:  "My Game",
+						To: []mailTo{
+							{Email: "player@example.com", Type: "to"},
+							{Email: "friend@example.com", Name: "Friend", Type: "to"},
+							{Email: "support@mygame.com", Type: "cc"},
+							{Email: "audit@mygame.com", Type: "bcc"},
+						},
+						Headers:            map[string]string{"Reply-To": "support@mygame.com"},
+						PreserveRecipients: &preserveRecipients,
+						Tags:               []string{"verify", "account"},
+						GlobalMergeVars:    []mergeVar{{Name: "code", Content: "123456"}},
+						Metadata:           map[string]string{"namespace": "mygame"},
+					},
+				},
+				TemplateName: "verify",
+			},
+		},
+		{
+			name: "content",
+			emailData: object.EmailData{
+				From:     "noreply@mygame.com",
+				To:       "player@example.com",
+				Subject:  "Verify",
+				TextBody: "Your code is 123456",
+				HTMLBody: "<p>Your code is 123456</p>",
+			},
+			expectedPath: sendEmailPath,
+			expectedPayload: templateEmailPayload{
+				emailPayload: emailPayload{
+					Key: "key-123",
+					Message: message{
+						Subject:         "Verify",
+						HTML:            "<p>Your code is 123456</p>",
+						Text:            "Your code is 123456",
+						FromEmail:       "noreply@mygame.com",
+						To:              []mailTo{{Email: "player@example.com", Type: "to"}},
+						GlobalMergeVars: []mergeVar{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var path string
+			var payload templateEmailPayload
+			server := newTestServer(t, http.StatusOK, `[{"email":"player@example.com","status":"sent","_id":"abc123"}]`, &path, &payload)
+			defer server.Close()
+
+			sender := NewMandrillClientWithAPIKey(server.URL, "key-123").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), testCase.emailData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.MessageID != "abc123" {
+				t.Errorf("expected message id abc123, got %q", result.MessageID)
+			}
+			if path != testCase.expectedPath {
+				t.Errorf("expected path %s, got %s", testCase.expectedPath, path)
+			}
+			if !reflect.DeepEqual(payload, testCase.expectedPayload) {
+				t.Errorf("expected payload %+v, got %+v", testCase.expectedPayload, payload)
+			}
+		})
+	}
+}
+
+func TestMailSender_SendWithResult_RecipientStatus(t *testing.T) {
+	testCases := []struct {
+		name              string
+		response          string
+		expectErr         bool
+		expectedMessageID string
+		expectedAccepted  []string
+		expectedRejected  []string
+	}{
+		{
+			name:              "all sent or queued",
+			response:          `[{"email":"player@example.com","status":"sent","_id":"abc123"},{"email":"support@mygame.com","status":"queued","_id":"def456"}]`,
+			expectedMessageID: "abc123",
+			expectedAccepted:  []string{"player@example.com", "support@mygame.com"},
+		},
+		{
+			name:              "rejected and invalid recipients",
+			response:          `[{"email":"player@example.com","status":"rejected","reject_reason":"hard-bounce","_id":"abc123"},{"email":"support@mygame.com","status":"sent","_id":"def456"},{"email":"audit@mygame","status":"invalid","_id":"ghi789"}]`,
+			expectErr:         true,
+			expectedMessageID: "def456",
+			expectedAccepted:  []string{"support@mygame.com"},
+			expectedRejected:  []string{"player@example.com", "audit@mygame"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := newTestServer(t, http.StatusOK, testCase.response, nil, nil)
+			defer server.Close()
+
+			sender := NewMandrillClientWithAPIKey(server.URL, "key-123").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), object.EmailData{
+				From:        "noreply@mygame.com",
+				To:          "player@example.com",
+				CarbonCopy:  []string{"support@mygame.com"},
+				XMCTemplate: "verify",
+			})
+			if testCase.expectErr {
+				var platformErr *platform.Error
+				if !errors.As(err, &platformErr) || !errors.Is(err, platform.ErrInvalidRecipient) {
+					t.Fatalf("expected ErrInvalidRecipient, got %v", err)
+				}
+				if platformErr.Retryable {
+					t.Error("expected not retryable")
+				}
+				if !reflect.DeepEqual(platformErr.Recipients, testCase.expectedRejected) {
+					t.Errorf("expected error recipients %v, got %v", testCase.expectedRejected, platformErr.Recipients)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			// the result is returned along with the error, so the accepted recipients are known
+			if result == nil {
+				t.Fatal("expected result")
+			}
+			if result.MessageID != testCase.expectedMessageID {
+				t.Errorf("expected message id %s, got %s", testCase.expectedMessageID, result.MessageID)
+			}
+			if !reflect.DeepEqual(result.AcceptedRecipients, testCase.expectedAccepted) {
+				t.Errorf("expected accepted %v, got %v", testCase.expectedAccepted, result.AcceptedRecipients)
+			}
+			if !reflect.DeepEqual(result.RejectedRecipients, testCase.expectedRejected) {
+				t.Errorf("expected rejected %v, got %v", testCase.expectedRejected, result.RejectedRecipients)
+			}
+		})
+	}
+}
+
+func TestMailSender_SendWithResult_Error(t *testing.T) {
+	testCases := []struct {
+		name              string
+		statusCode        int
+		body              string
+		expectedKind      error
+		expectedCode      string
+		expectedRetryable bool
+	}{
+		{
+			name:         "invalid key",
+			statusCode:   http.StatusInternalServerError,
+			body:         `{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`,
+			expectedKind: platform.ErrUnauthorized,
+			expectedCode: "Invalid_Key",
+		},
+		{
+			name:         "unknown template",
+			statusCode:   http.StatusInternalServerError,
+			body:         `{"status":"error","code":5,"name":"Unknown_Template","message":"No such template \"verify\""}`,
+			expectedKind: platform.ErrBadRequest,
+			expectedCode: "Unknown_Template",
+		},
+		{
+			name:         "other named error is a client error",
+			statusCode:   http.StatusInternalServerError,
+			body:         `{"status":"error","code":12,"name":"Unknown_Sender","message":"No such sender"}`,
+			expectedKind: platform.ErrBadRequest,
+			expectedCode: "Unknown_Sender",
+		},
+		{
+			name:              "general error",
+			statusCode:        http.StatusInternalServerError,
+			body:              `{"status":"error","code":-1,"name":"GeneralError","message":"An unexpected error occurred"}`,
+			expectedKind:      platform.ErrProviderUnavailable,
+			expectedCode:      "GeneralError",
+			expectedRetryable: true,
+		},
+		{
+			name:              "unavailable without error body",
+			statusCode:        http.StatusBadGateway,
+			body:              "Bad Gateway",
+			expectedKind:      platform.ErrProviderUnavailable,
+			expectedRetryable: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := newTestServer(t, testCase.statusCode, testCase.body, nil, nil)
+			defer server.Close()
+
+			sender := NewMandrillClientWithAPIKey(server.URL, "key-123").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), object.EmailData{
+				From:        "noreply@mygame.com",
+				To:          "player@example.com",
+				XMCTemplate: "verify",
+			})
+			if result != nil {
+				t.Errorf("expected no result, got %+v", result)
+			}
+
+			var platformErr *platform.Error
+			if !errors.As(err, &platformErr) {
+				t.Fatalf("expected *platform.Error, got %v", err)
+			}
+			if !errors.Is(err, testCase.expectedKind) {
+				t.Errorf("expected kind %v, got %v", testCase.expectedKind, platformErr.Kind)
+			}
+			if platformErr.Code != testCase.expectedCode {
+				t.Errorf("expected code %q, got %q", testCase.expectedCode, platformErr.Code)
+			}
+			if platformErr.Retryable != testCase.expectedRetryable {
+				t.Errorf("expected retryable %v", testCase.expectedRetryable)
+			}
+		})
+	}
+}
+
+func TestMailSender_SendBatch(t *testing.T) {
+	var payload templateEmailPayload
+	server := newTestServer(t, http.StatusOK, `[{"email":"a@example.com","status":"sent","_id":"1"},{"email":"b@example.com","status":"sent","_id":"2"}]`, nil, &payload)
+	defer server.Close()
+
+	sender := NewMandrillClientWithAPIKey(server.URL, "key-123").(*MailSender)
+	_, err := sender.SendBatch(context.Background(), object.EmailData{
+		From:        "noreply@mygame.com",
+		XMCTemplate: "newsletter",
+	}, []object.Recipient{
+		{Address: mail.Address{Address: "a@example.com"}, MergeVars: map[string]interface{}{"name": "A"}},
+		{Address: mail.Address{Name: "B", Address: "b@example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the recipients never see each other, even with cc preserved by the account setting
+	if payload.Message.PreserveRecipients == nil || *payload.Message.PreserveRecipients {
+		t.Error("expected preserve_recipients false")
+	}
+	expectedTo := []mailTo{{Email: "a@example.com", Type: "to"}, {Email: "b@example.com", Name: "B", Type: "to"}}
+	if !reflect.DeepEqual(payload.Message.To, expectedTo) {
+		t.Errorf("expected to %v, got %v", expectedTo, payload.Message.To)
+	}
+	expectedMergeVars := []recipientMergeVars{{Rcpt: "a@example.com", Vars: []mergeVar{{Name: "name", Content: "A"}}}}
+	if !reflect.DeepEqual(payload.Message.MergeVars, expectedMergeVars) {
+		t.Errorf("expected merge vars %v, got %v", expectedMergeVars, payload.Message.MergeVars)
+	}
+}
@@ -22,9 +22,12 @@ import (
 	"fmt"
 	"net/mail"
 	"net/smtp"
+	"strings"
 
+	"github.com/AccelByte/justice-go-common-email/constant"
 	"github.com/AccelByte/justice-go-common-email/object"
 	"github.com/AccelByte/justice-go-common-email/platform"
+	smtpplatform "github.com/AccelByte/justice-go-common-email/platform/smtp"
 	"github.com/sirupsen/logrus"
 )
 
@@ -38,19 +41,93 @@ func NewMandrillClientWithSMTP(smtpHost string, smtpPort int, smtpUsername, smtp
 }
 
 func (e SMTPMailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e SMTPMailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	var msg []byte
+	var recipients []string
+	var err error
+	if emailData.HTMLBody != "" || emailData.TextBody != "" {
+		msg, recipients, err = newContentMessage(emailData)
+	} else {
+		msg, recipients, err = newTemplateMessage(emailData)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	auth := smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	result := platform.NewSendResult(PlatformID)
+	err = sendSMTPMail(
+		fmt.Sprintf("%s:%d", e.Host, e.Port),
+		auth,
+		emailData.From,
+		recipients,
+		msg,
+	)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using Mandrill SMTP: %s", emailData.To, err)
+		return nil, platform.NewSMTPError(PlatformID, err)
+	}
+	return result.Complete(emailData), nil
+}
+
+// newContentMessage builds the MIME message of the email content, e.g. rendered by the local template renderer.
+// The Mandrill options are sent as the X-MC-* headers.
+func newContentMessage(emailData object.EmailData) ([]byte, []string, error) {
+	message, err := smtpplatform.NewMessage(emailData)
+	if err != nil {
+		return nil, nil, err
+	}
+	message.Headers = map[string]string{}
+	if len(emailData.CarbonCopy) > 0 {
+		message.Headers["X-MC-PreserveRecipients"] = "true"
+	}
+	if len(emailData.Categories) > 0 {
+		message.Headers["X-MC-Tags"] = strings.Join(emailData.Categories, ",")
+	}
+	if emailData.Namespace != "" {
+		message.Headers["X-MC-Metadata"] = getMetadataHeader(emailData.Namespace)
+	}
+	msg, err := message.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, message.Recipients(), nil
+}
+
+// newTemplateMessage builds the message of the Mandrill template, the content is rendered by Mandrill from X-MC-Template.
+func newTemplateMessage(emailData object.EmailData) ([]byte, []string, error) {
 	mergeVars, err := json.Marshal(emailData.XMCMergeVars)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	from := mail.Address{Address: emailData.From, Name: emailData.FromName}
-	toAddress := mail.Address{Address: emailData.To}
+	toAddresses := emailData.GetToAddresses()
+	toHeaderValues := make([]string, 0, len(toAddresses))
+	recipients := make([]string, 0, len(toAddresses)+len(emailData.CarbonCopy)+len(emailData.Bcc))
+	for _, toAddress := range toAddresses {
+		toHeaderValues = append(toHeaderValues, toAddress.String())
+		recipients = append(recipients, toAddress.Address)
+	}
+	ccHeaderValues := make([]string, 0, len(emailData.CarbonCopy))
+	for _, ccAddress := range emailData.CarbonCopy {
+		ccHeaderValues = append(ccHeaderValues, (&mail.Address{Address: ccAddress}).String())
+		recipients = append(recipients, ccAddress)
+	}
+	// Bcc recipients are only added to the envelope
+	for _, bccAddress := range emailData.Bcc {
+		recipients = append(recipients, bccAddress.Address)
+	}
 
 	headerKeys := []string{"Return-Path", "From", "To", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"}
 	header := make(map[string]string)
 	header["Return-Path"] = from.Address
 	header["From"] = from.String()
-	header["To"] = toAddress.String()
+	header["To"] = strings.Join(toHeaderValues, ", ")
 	header["MIME-Version"] = "1.0"
 	header["Content-Type"] = "text/plain; charset=\"utf-8\""
 	header["Content-Transfer-Encoding"] = "base64"
@@ -59,6 +136,19 @@ func (e SMTPMailSender) Send(ctx context.Context, emailData object.EmailData) er
 		header["X-MC-MergeVars"] = string(mergeVars)
 		headerKeys = append(headerKeys, "X-MC-Template", "X-MC-MergeVars")
 	}
+	if len(ccHeaderValues) > 0 {
+		header["Cc"] = strings.Join(ccHeaderValues, ", ")
+		header["X-MC-PreserveRecipients"] = "true"
+		headerKeys = append(headerKeys, "Cc", "X-MC-PreserveRecipients")
+	}
+	if len(emailData.Categories) > 0 {
+		header["X-MC-Tags"] = strings.Join(emailData.Categories, ",")
+		headerKeys = append(headerKeys, "X-MC-Tags")
+	}
+	if emailData.Namespace != "" {
+		header["X-MC-Metadata"] = getMetadataHeader(emailData.Namespace)
+		headerKeys = append(headerKeys, "X-MC-Metadata")
+	}
 	if emailData.ReplyTo != "" {
 		replyTo := mail.Address{Address: emailData.ReplyTo}
 		header["Reply-To"] = replyTo.String()
@@ -68,19 +158,13 @@ func (e SMTPMailSender) Send(ctx context.Context, emailData object.EmailData) er
 	for _, key := range headerKeys {
 		msg += fmt.Sprintf("%s: %s\r\n", key, header[key])
 	}
+	return []byte(msg), recipients, nil
+}
 
-	auth := smtp.PlainAuth("", e.Username, e.Password, e.Host)
-	err = sendSMTPMail(
-		fmt.Sprintf("%s:%d", e.Host, e.Port),
-		auth,
-		from.Address,
-		[]string{toAddress.Address},
-		[]byte(msg),
-	)
-	if err != nil {
-		logrus.Errorf("Error send email to %s using Mandrill SMTP: %s", emailData.To, err)
-	}
-	return err
+// getMetadataHeader returns the X-MC-Metadata header value, so the webhook events of the email report its namespace.
+func getMetadataHeader(namespace string) string {
+	metadata, _ := json.Marshal(map[string]string{constant.NamespaceMetadataKey: namespace})
+	return string(metadata)
 }
 
 func sendSMTPMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
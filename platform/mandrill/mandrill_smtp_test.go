@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mandrill
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+func TestNewContentMessage(t *testing.T) {
+	emailData := object.EmailData{
+		Namespace:  "mygame",
+		From:       "noreply@mygame.com",
+		To:         "player@example.com",
+		CarbonCopy: []string{"support@mygame.com"},
+		Bcc:        []mail.Address{{Address: "audit@mygame.com"}},
+		Subject:    "Verify your account",
+		TextBody:   "Your code is 123456",
+		HTMLBody:   "<p>Your code is 123456</p>",
+		Categories: []string{"verify", "account"},
+		Attachments: []object.Attachment{
+			{Filename: "terms.txt", ContentType: "text/plain", Reader: strings.NewReader("terms")},
+		},
+	}
+
+	msg, recipients, err := newContentMessage(emailData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "player@example.com,support@mygame.com,audit@mygame.com"; strings.Join(recipients, ",") != expected {
+		t.Errorf("expected recipients %s, got %v", expected, recipients)
+	}
+
+	message, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHeaders := map[string]string{
+		"Subject":                 "Verify your account",
+		"To":                      "<player@example.com>",
+		"Cc":                      "<support@mygame.com>",
+		"Bcc":                     "",
+		"X-Mc-Tags":               "verify,account",
+		"X-Mc-Preserverecipients": "true",
+		"X-Mc-Template":           "",
+		"X-Mc-Metadata":           `{"namespace":"mygame"}`,
+	}
+	for key, expected := range expectedHeaders {
+		if value := message.Header.Get(key); value != expected {
+			t.Errorf("expected %s header %q, got %q", key, expected, value)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("expected multipart/mixed, got %s %v", mediaType, err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	var contents []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		content, _ := ioutil.ReadAll(part)
+		contents = append(contents, part.Header.Get("Content-Type")+": "+string(content))
+	}
+	if len(contents) != 2 || !strings.Contains(contents[0], "Your code is 123456") || !strings.Contains(contents[1], "terms") {
+		t.Errorf("expected the content and the attachment, got %q", contents)
+	}
+}
+
+func TestNewTemplateMessage(t *testing.T) {
+	msg, recipients, err := newTemplateMessage(object.EmailData{
+		From:         "noreply@mygame.com",
+		To:           "player@example.com",
+		XMCTemplate:  "verify",
+		XMCMergeVars: map[string]interface{}{"code": "123456"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 1 || recipients[0] != "player@example.com" {
+		t.Errorf("expected player@example.com recipient, got %v", recipients)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := message.Header.Get("X-MC-Template"); value != "verify" {
+		t.Errorf("expected X-MC-Template verify, got %q", value)
+	}
+	if value := message.Header.Get("X-MC-MergeVars"); value != `{"code":"123456"}` {
+		t.Errorf("expected X-MC-MergeVars, got %q", value)
+	}
+}
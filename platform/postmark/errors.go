@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package postmark
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// Postmark API error codes, see https://postmarkapp.com/developer/api/overview#error-codes
+const (
+	ErrorCodeInvalidAPIToken          = 10
+	ErrorCodeInvalidEmailRequest      = 300
+	ErrorCodeSenderSignatureNotFound  = 400
+	ErrorCodeSenderSignatureNotActive = 401
+	ErrorCodeNotAllowedToSend         = 405
+	ErrorCodeInactiveRecipient        = 406
+	ErrorCodeAccountPending           = 412
+	ErrorCodeAccountMayNotSend        = 413
+	ErrorCodeTemplateNotFound         = 1101
+)
+
+var (
+	ErrInvalidAPIToken        = errors.New("postmark: invalid or missing server token")
+	ErrInvalidEmailRequest    = errors.New("postmark: invalid email request")
+	ErrSenderSignatureInvalid = errors.New("postmark: sender signature not found or not confirmed")
+	ErrNotAllowedToSend       = errors.New("postmark: account is not allowed to send")
+	ErrInactiveRecipient      = errors.New("postmark: inactive recipient")
+	ErrTemplateNotFound       = errors.New("postmark: template not found")
+	ErrRateLimited            = errors.New("postmark: rate limited")
+)
+
+// Error is the Postmark error response, returned by MailSender.Send wrapped in *platform.Error.
+// Use errors.Is with the sentinel errors above to check the Postmark specific failure kind.
+type Error struct {
+	StatusCode int
+	ErrorCode  int    `json:"ErrorCode"`
+	Message    string `json:"Message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("postmark error %d (HTTP %d): %s", e.ErrorCode, e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	return e.sentinel() == target
+}
+
+func (e *Error) sentinel() error {
+	switch e.ErrorCode {
+	case ErrorCodeInvalidAPIToken:
+		return ErrInvalidAPIToken
+	case ErrorCodeInvalidEmailRequest:
+		return ErrInvalidEmailRequest
+	case ErrorCodeSenderSignatureNotFound, ErrorCodeSenderSignatureNotActive:
+		return ErrSenderSignatureInvalid
+	case ErrorCodeNotAllowedToSend, ErrorCodeAccountPending, ErrorCodeAccountMayNotSend:
+		return ErrNotAllowedToSend
+	case ErrorCodeInactiveRecipient:
+		return ErrInactiveRecipient
+	case ErrorCodeTemplateNotFound:
+		return ErrTemplateNotFound
+	}
+	if e.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// toPlatformError wraps the Postmark error into platform error.
+func (e *Error) toPlatformError(header http.Header) *platform.Error {
+	platformErr := platform.NewHTTPError(PlatformID, e.StatusCode, header, e.Message)
+	platformErr.Err = e
+	if e.ErrorCode != 0 {
+		platformErr.Code = strconv.Itoa(e.ErrorCode)
+	}
+
+	switch e.sentinel() {
+	case ErrInvalidAPIToken, ErrNotAllowedToSend, ErrSenderSignatureInvalid:
+		platformErr.Kind = platform.ErrUnauthorized
+		platformErr.Retryable = false
+	case ErrInactiveRecipient:
+		platformErr.Kind = platform.ErrInvalidRecipient
+		platformErr.Retryable = false
+	case ErrInvalidEmailRequest, ErrTemplateNotFound:
+		platformErr.Kind = platform.ErrBadRequest
+		platformErr.Retryable = false
+	}
+	return platformErr
+}
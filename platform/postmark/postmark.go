@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package postmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PlatformID = "postmark"
+
+	ConfigKeyServerToken   = "server_token"
+	ConfigKeyMessageStream = "message_stream"
+	ConfigKeyAPIURL        = "api_url"
+
+	apiHost               = "https://api.postmarkapp.com"
+	sendEmailPath         = "/email"
+	sendTemplateEmailPath = "/email/withTemplate"
+
+	DefaultMessageStream = "outbound"
+)
+
+func init() {
+	platform.Register(PlatformID, NewPostmarkClientFromConfig)
+}
+
+type MailSender struct {
+	Host          string
+	ServerToken   string
+	MessageStream string
+}
+
+type attachment struct {
+	Name        string `json:"Name"`
+	Content     string `json:"Content"`
+	ContentType string `json:"ContentType"`
+	ContentID   string `json:"ContentID,omitempty"`
+}
+
+type sendResponse struct {
+	To          string    `json:"To"`
+	SubmittedAt time.Time `json:"SubmittedAt"`
+	MessageID   string    `json:"MessageID"`
+}
+
+type emailPayload struct {
+	From          string       `json:"From"`
+	To            string       `json:"To"`
+	Cc            string       `json:"Cc,omitempty"`
+	Bcc           string       `json:"Bcc,omitempty"`
+	ReplyTo       string       `json:"ReplyTo,omitempty"`
+	Subject       string       `json:"Subject,omitempty"`
+	HTMLBody      string       `json:"HtmlBody,omitempty"`
+	TextBody      string       `json:"TextBody,omitempty"`
+	Tag           string       `json:"Tag,omitempty"`
+	MessageStream string       `json:"MessageStream,omitempty"`
+	Attachments   []attachment `json:"Attachments,omitempty"`
+}
+
+type templateEmailPayload struct {
+	*emailPayload
+	TemplateAlias string                 `json:"TemplateAlias"`
+	TemplateModel map[string]interface{} `json:"TemplateModel"`
+}
+
+func NewPostmarkClient(apiURL, serverToken, messageStream string) platform.SenderPlatform {
+	if apiURL == "" {
+		apiURL = apiHost
+	}
+	if messageStream == "" {
+		messageStream = DefaultMessageStream
+	}
+	return &MailSender{
+		Host:          apiURL,
+		ServerToken:   serverToken,
+		MessageStream: messageStream,
+	}
+}
+
+// NewPostmarkClientFromConfig creates Postmark sender platform from the generic platform configuration.
+func NewPostmarkClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	serverToken, err := config.GetRequired(ConfigKeyServerToken)
+	if err != nil {
+		return nil, err
+	}
+	return NewPostmarkClient(config.Get(ConfigKeyAPIURL), serverToken, config.Get(ConfigKeyMessageStream)), nil
+}
+
+func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if emailData.XMCTemplate == "" && emailData.HTMLBody == "" && emailData.TextBody == "" {
+		return nil, errors.New("postmark: neither template alias nor content is specified")
+	}
+
+	from := mail.Address{Address: emailData.From, Name: emailData.FromName}
+	payload := &emailPayload{
+		From:          from.String(),
+		To:            joinAddresses(emailData.GetToAddresses()),
+		Cc:            strings.Join(emailData.CarbonCopy, ","),
+		Bcc:           joinAddresses(emailData.Bcc),
+		ReplyTo:       emailData.ReplyTo,
+		MessageStream: e.MessageStream,
+	}
+	// Postmark only supports a single tag per message
+	if len(emailData.Categories) > 0 {
+		payload.Tag = emailData.Categories[0]
+	}
+	for i := range emailData.Attachments {
+		content, err := emailData.Attachments[i].GetContent()
+		if err != nil {
+			return nil, err
+		}
+		file := attachment{
+			Name:        emailData.Attachments[i].Filename,
+			Content:     base64.StdEncoding.EncodeToString(content),
+			ContentType: emailData.Attachments[i].GetContentType(),
+		}
+		if emailData.Attachments[i].IsInline() && emailData.Attachments[i].ContentID != "" {
+			file.ContentID = "cid:" + emailData.Attachments[i].ContentID
+		}
+		payload.Attachments = append(payload.Attachments, file)
+	}
+
+	// the email without template, e.g. rendered locally, is sent with its own content
+	path := sendEmailPath
+	var payloadBytes []byte
+	var err error
+	if emailData.XMCTemplate != "" {
+		path = sendTemplateEmailPath
+		payloadBytes, err = json.Marshal(templateEmailPayload{
+			emailPayload:  payload,
+			TemplateAlias: emailData.XMCTemplate,
+			TemplateModel: emailData.XMCMergeVars,
+		})
+	} else {
+		payload.Subject = emailData.Subject
+		payload.HTMLBody = emailData.HTMLBody
+		payload.TextBody = emailData.TextBody
+		payloadBytes, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		logrus.Errorf("Error send email to %s using postmark: %s", emailData.To, err)
+		return nil, err
+	}
+	req.Header.Set("X-Postmark-Server-Token", e.ServerToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := &http.Client{
+		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
+	}
+	result := platform.NewSendResult(PlatformID)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using postmark: %s", emailData.To, err)
+		return nil, platform.NewTransportError(PlatformID, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		errorsResponseBody, errReadResp := ioutil.ReadAll(resp.Body)
+		if errReadResp != nil {
+			return nil, errReadResp
+		}
+		logrus.Errorf("Error send email to %s using postmark: %s", emailData.To, string(errorsResponseBody))
+
+		postmarkErr := &Error{StatusCode: resp.StatusCode}
+		if errUnmarshal := json.Unmarshal(errorsResponseBody, postmarkErr); errUnmarshal != nil {
+			postmarkErr.Message = string(errorsResponseBody)
+		}
+		return nil, postmarkErr.toPlatformError(resp.Header)
+	}
+
+	response := &sendResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		logrus.Warnf("Unable to read postmark message id of email to %s: %s", emailData.To, err)
+	}
+	result.MessageID = response.MessageID
+	return result.Complete(emailData), nil
+}
+
+func joinAddresses(addresses []mail.Address) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		formatted = append(formatted, object.FormatAddress(address))
+	}
+	return strings.Join(formatted, ",")
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package postmark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+func TestMailSender_SendWithResult_Payload(t *testing.T) {
+	testCases := []struct {
+		name            string
+		emailData       object.EmailData
+		expectedPath    string
+		expectedPayload map[string]interface{}
+	}{
+		{
+			name: "template",
+			emailData: object.EmailData{
+				From:         "noreply@mygame.com",
+				FromName:     "My Game",
+				To:           "player@example.com",
+				ToList:       []mail.Address{{Name: "Friend", Address: "friend@example.com"}},
+				Bcc:          []mail.Address{{Address: "audit@mygame.com"}},
+				CarbonCopy:   []string{"support@mygame.com", "qa@mygame.com"},
+				ReplyTo:      "support@mygame.com",
+				Subject:      "Verify",
+				Categories:   []string{"verify", "account"},
+				XMCTemplate:  "verify",
+				XMCMergeVars: map[string]interface{}{"code": "123456"},
+			},
+			expectedPath: "/email/withTemplate",
+			expectedPayload: map[string]interface{}{
+				"From":          `"My Game" <noreply@mygame.com>`,
+				"To":            `player@example.com,"Friend" <friend@example.com>`,
+				"Cc":            "support@mygame.com,qa@mygame.com",
+				"Bcc":           "audit@mygame.com",
+				"ReplyTo":       "support@mygame.com",
+				"Tag":           "verify",
+				"MessageStream": "broadcast",
+				"TemplateAlias": "verify",
+				"TemplateModel": map[string]interface{}{"code": "123456"},
+			},
+		},
+		{
+			name: "content",
+			emailData: object.EmailData{
+				From:     "noreply@mygame.com",
+				To:       "player@example.com",
+				Subject:  "Verify",
+				TextBody: "Your code is 123456",
+				HTMLBody: `<p>Your code is 123456</p><img src="cid:logo">`,
+				Attachments: []object.Attachment{
+					{Filename: "terms.txt", Reader: strings.NewReader("terms")},
+					{Filename: "logo.png", ContentType: "image/png", Content: []byte("png"), Disposition: object.AttachmentDispositionInline, ContentID: "logo"},
+				},
+			},
+			expectedPath: "/email",
+			expectedPayload: map[string]interface{}{
+				"From":          "<noreply@mygame.com>",
+				"To":            "player@example.com",
+				"Subject":       "Verify",
+				"TextBody":      "Your code is 123456",
+				"HtmlBody":      `<p>Your code is 123456</p><img src="cid:logo">`,
+				"MessageStream": "broadcast",
+				"Attachments": []interface{}{
+					map[string]interface{}{"Name": "terms.txt", "Content": "dGVybXM=", "ContentType": "application/octet-stream"},
+					map[string]interface{}{"Name": "logo.png", "Content": "cG5n", "ContentType": "image/png", "ContentID": "cid:logo"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var request *http.Request
+			var payload map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Error(err)
+				}
+				_, _ = w.Write([]byte(`{"To":"player@example.com","SubmittedAt":"2023-01-01T00:00:00Z","MessageID":"b7bc2f4a-e38e-4336-af7d-e6c392c2f817","ErrorCode":0,"Message":"OK"}`))
+			}))
+			defer server.Close()
+
+			sender := NewPostmarkClient(server.URL, "token-123", "broadcast").(*MailSender)
+			result, err := sender.SendWithResult(context.Background(), testCase.emailData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.MessageID != "b7bc2f4a-e38e-4336-af7d-e6c392c2f817" {
+				t.Errorf("expected message id from the response, got %q", result.MessageID)
+			}
+
+			if request.Method != http.MethodPost || request.URL.Path != testCase.expectedPath {
+				t.Errorf("expected request POST %s, got %s %s", testCase.expectedPath, request.Method, request.URL.Path)
+			}
+			if token := request.Header.Get("X-Postmark-Server-Token"); token != "token-123" {
+				t.Errorf("unexpected server token %q", token)
+			}
+			if !reflect.DeepEqual(payload, testCase.expectedPayload) {
+				t.Errorf("expected payload %v, got %v", testCase.expectedPayload, payload)
+			}
+		})
+	}
+}
+
+func TestMailSender_SendWithResult_NoContent(t *testing.T) {
+	sender := NewPostmarkClient("http://127.0.0.1:1", "token-123", "").(*MailSender)
+	if _, err := sender.SendWithResult(context.Background(), object.EmailData{From: "noreply@mygame.com", To: "player@example.com"}); err == nil {
+		t.Error("expected error of the email without template and content")
+	}
+}
+
+func TestMailSender_SendWithResult_Error(t *testing.T) {
+	testCases := []struct {
+		name              string
+		statusCode        int
+		body              string
+		expectedKind      error
+		expectedPostmark  error
+		expectedCode      string
+		expectedRetryable bool
+		expectedMessage   string
+	}{
+		{
+			name:             "invalid api token",
+			statusCode:       http.StatusUnauthorized,
+			body:             `{"ErrorCode":10,"Message":"No Account or Server API tokens were supplied in the HTTP headers."}`,
+			expectedKind:     platform.ErrUnauthorized,
+			expectedPostmark: ErrInvalidAPIToken,
+			expectedCode:     "10",
+			expectedMessage:  "No Account or Server API tokens were supplied in the HTTP headers.",
+		},
+		{
+			name:             "sender signature not confirmed",
+			statusCode:       http.StatusUnprocessableEntity,
+			body:             `{"ErrorCode":401,"Message":"Sender signature not confirmed."}`,
+			expectedKind:     platform.ErrUnauthorized,
+			expectedPostmark: ErrSenderSignatureInvalid,
+			expectedCode:     "401",
+			expectedMessage:  "Sender signature not confirmed.",
+		},
+		{
+			name:             "account pending",
+			statusCode:       http.StatusUnprocessableEntity,
+			body:             `{"ErrorCode":412,"Message":"Account is pending approval."}`,
+			expectedKind:     platform.ErrUnauthorized,
+			expectedPostmark: ErrNotAllowedToSend,
+			expectedCode:     "412",
+			expectedMessage:  "Account is pending approval.",
+		},
+		{
+			name:             "inactive recipient",
+			statusCode:       http.StatusUnprocessableEntity,
+			body:             `{"ErrorCode":406,"Message":"You tried to send to a recipient that has been marked as inactive."}`,
+			expectedKind:     platform.ErrInvalidRecipient,
+			expectedPostmark: ErrInactiveRecipient,
+			expectedCode:     "406",
+			expectedMessage:  "You tried to send to a recipient that has been marked as inactive.",
+		},
+		{
+			name:             "template not found",
+			statusCode:       http.StatusUnprocessableEntity,
+			body:             `{"ErrorCode":1101,"Message":"The 'TemplateAlias' associated with this request is not valid or was not found."}`,
+			expectedKind:     platform.ErrBadRequest,
+			expectedPostmark: ErrTemplateNotFound,
+			expectedCode:     "1101",
+			expectedMessage:  "The 'TemplateAlias' associated with this request is not valid or was not found.",
+		},
+		{
+			name:             "invalid email request",
+			statusCode:       http.StatusUnprocessableEntity,
+			body:             `{"ErrorCode":300,"Message":"Invalid 'To' address."}`,
+			expectedKind:     platform.ErrBadRequest,
+			expectedPostmark: ErrInvalidEmailRequest,
+			expectedCode:     "300",
+			expectedMessage:  "Invalid 'To' address.",
+		},
+		{
+			name:              "rate limited",
+			statusCode:        http.StatusTooManyRequests,
+			body:              `{"ErrorCode":0,"Message":"Rate limit exceeded."}`,
+			expectedKind:      platform.ErrRateLimited,
+			expectedPostmark:  ErrRateLimited,
+			expectedRetryable: true,
+			expectedMessage:   "Rate limit exceeded.",
+		},
+		{
+			name:              "unavailable with non JSON body",
+			statusCode:        http.StatusServiceUnavailable,
+			body:              "Service Unavailable",
+			expectedKind:      platform.ErrProviderUnavailable,
+			expectedRetryable: true,
+			expectedMessage:   "Service Unavailable",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(testCase.statusCode)
+				_, _ = w.Write([]byte(testCase.body))
+			}))
+			defer server.Close()
+
+			sender := NewPostmarkClient(server.URL, "token-123", "").(*MailSender)
+			_, err := sender.SendWithResult(context.Background(), object.EmailData{
+				From:        "noreply@mygame.com",
+				To:          "player@example.com",
+				XMCTemplate: "verify",
+			})
+
+			var platformErr *platform.Error
+			if !errors.As(err, &platformErr) {
+				t.Fatalf("expected *platform.Error, got %v", err)
+			}
+			if !errors.Is(err, testCase.expectedKind) {
+				t.Errorf("expected kind %v, got %v", testCase.expectedKind, platformErr.Kind)
+			}
+			if testCase.expectedPostmark != nil && !errors.Is(err, testCase.expectedPostmark) {
+				t.Errorf("expected postmark error %v, got %v", testCase.expectedPostmark, platformErr.Err)
+			}
+			if platformErr.Code != testCase.expectedCode {
+				t.Errorf("expected code %q, got %q", testCase.expectedCode, platformErr.Code)
+			}
+			if platformErr.Retryable != testCase.expectedRetryable {
+				t.Errorf("expected retryable %v", testCase.expectedRetryable)
+			}
+			if platformErr.Message != testCase.expectedMessage {
+				t.Errorf("expected message %q, got %q", testCase.expectedMessage, platformErr.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Mode string
+
+const (
+	// ModeBlock waits until the request is allowed or the context is done.
+	ModeBlock Mode = "block"
+	// ModeFailFast returns ErrRateLimitExceeded immediately if the request is not allowed.
+	ModeFailFast Mode = "fail_fast"
+)
+
+var ErrRateLimitExceeded = errors.New("client-side rate limit exceeded")
+
+// Limit is the token bucket limit. Zero Rate means unlimited.
+type Limit struct {
+	// Rate is the emails per second.
+	Rate float64
+	// Burst is the max emails sent at once. Default: Rate, at least 1.
+	Burst int
+}
+
+func (l Limit) IsUnlimited() bool {
+	return l.Rate <= 0
+}
+
+func (l Limit) getBurst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	if l.Rate > 1 {
+		return float64(int(l.Rate))
+	}
+	return 1
+}
+
+func ParseMode(value string) (Mode, error) {
+	switch Mode(value) {
+	case "":
+		return ModeBlock, nil
+	case ModeBlock, ModeFailFast:
+		return Mode(value), nil
+	default:
+		return "", fmt.Errorf("rate limit mode %s is not valid", value)
+	}
+}
+
+// Limiter is a token bucket rate limiter.
+type Limiter struct {
+	lock   sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+	// now returns the current time, replaced by the tests
+	now func() time.Time
+}
+
+func NewLimiter(limit Limit) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		tokens: limit.getBurst(),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+func (l *Limiter) Limit() Limit {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.limit
+}
+
+// SetLimit changes the limit, keeping the tokens available up to the new burst.
+func (l *Limiter) SetLimit(limit Limit) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.refill(l.now())
+	l.limit = limit
+	if burst := limit.getBurst(); l.tokens > burst {
+		l.tokens = burst
+	}
+}
+
+// Take takes a token, waiting for it in ModeBlock or failing with ErrRateLimitExceeded in ModeFailFast.
+func (l *Limiter) Take(ctx context.Context, mode Mode) error {
+	l.lock.Lock()
+	if l.limit.IsUnlimited() {
+		l.lock.Unlock()
+		return nil
+	}
+	now := l.now()
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		l.lock.Unlock()
+		return nil
+	}
+	wait := time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+	if mode == ModeFailFast {
+		l.lock.Unlock()
+		return ErrRateLimitExceeded
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+		l.lock.Unlock()
+		return ErrRateLimitExceeded
+	}
+	// the token is reserved in advance, so the waiting requests are served in order
+	l.tokens--
+	l.lock.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		l.tokens++
+		l.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.limit.Rate
+		if burst := l.limit.getBurst(); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+}
+
+// Group holds a limiter per key, e.g. per namespace or per provider API key.
+type Group struct {
+	Limit Limit
+
+	lock     sync.Mutex
+	limiters map[string]*Limiter
+}
+
+func NewGroup(limit Limit) *Group {
+	return &Group{
+		Limit:    limit,
+		limiters: make(map[string]*Limiter),
+	}
+}
+
+// Get returns the limiter of the key with the group limit, creating it if it doesn't exist yet.
+func (g *Group) Get(key string) *Limiter {
+	return g.GetWithLimit(key, g.Limit)
+}
+
+// GetWithLimit returns the limiter of the key, creating it or updating its limit if it's changed.
+func (g *Group) GetWithLimit(key string, limit Limit) *Limiter {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	limiter, found := g.limiters[key]
+	if !found {
+		limiter = NewLimiter(limit)
+		g.limiters[key] = limiter
+	} else if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+	return limiter
+}
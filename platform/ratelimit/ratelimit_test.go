@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(limit Limit) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	limiter := NewLimiter(limit)
+	limiter.now = clock.Now
+	limiter.last = clock.Now()
+	return limiter, clock
+}
+
+// take advances the clock and takes a token in ModeFailFast, expecting it to be allowed or not.
+type take struct {
+	advance time.Duration
+	allowed bool
+}
+
+func TestLimiter_Take(t *testing.T) {
+	testCases := []struct {
+		name  string
+		limit Limit
+		takes []take
+	}{
+		{
+			name:  "allows burst at once",
+			limit: Limit{Rate: 1, Burst: 3},
+			takes: []take{{allowed: true}, {allowed: true}, {allowed: true}, {allowed: false}},
+		},
+		{
+			name:  "refills at rate",
+			limit: Limit{Rate: 2, Burst: 1},
+			takes: []take{
+				{allowed: true},
+				{allowed: false},
+				{advance: 500 * time.Millisecond, allowed: true},
+				{advance: 250 * time.Millisecond, allowed: false},
+				{advance: 250 * time.Millisecond, allowed: true},
+			},
+		},
+		{
+			name:  "caps refill at burst",
+			limit: Limit{Rate: 10, Burst: 2},
+			takes: []take{
+				{allowed: true},
+				{allowed: true},
+				{allowed: false},
+				{advance: 10 * time.Second, allowed: true},
+				{allowed: true},
+				{allowed: false},
+			},
+		},
+		{
+			name:  "defaults burst to rate",
+			limit: Limit{Rate: 3},
+			takes: []take{{allowed: true}, {allowed: true}, {allowed: true}, {allowed: false}},
+		},
+		{
+			name:  "defaults burst to 1 below 1 per second",
+			limit: Limit{Rate: 0.5},
+			takes: []take{
+				{allowed: true},
+				{allowed: false},
+				{advance: time.Second, allowed: false},
+				{advance: time.Second, allowed: true},
+			},
+		},
+		{
+			name:  "unlimited",
+			limit: Limit{},
+			takes: []take{{allowed: true}, {allowed: true}, {allowed: true}, {allowed: true}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter, clock := newTestLimiter(tc.limit)
+			for i, tk := range tc.takes {
+				clock.now = clock.now.Add(tk.advance)
+				err := limiter.Take(context.Background(), ModeFailFast)
+				if tk.allowed && err != nil {
+					t.Fatalf("take %d: expected allowed, got %v", i, err)
+				}
+				if !tk.allowed && err != ErrRateLimitExceeded {
+					t.Fatalf("take %d: expected ErrRateLimitExceeded, got %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiter_SetLimit(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 5})
+	limiter.SetLimit(Limit{Rate: 1, Burst: 2})
+	for i, allowed := range []bool{true, true, false} {
+		if err := limiter.Take(context.Background(), ModeFailFast); (err == nil) != allowed {
+			t.Fatalf("take %d: expected allowed %v, got %v", i, allowed, err)
+		}
+	}
+	clock.now = clock.now.Add(time.Second)
+	if err := limiter.Take(context.Background(), ModeFailFast); err != nil {
+		t.Fatalf("expected allowed after refill, got %v", err)
+	}
+}
+
+func TestLimiter_TakeBlock(t *testing.T) {
+	t.Run("waits for token", func(t *testing.T) {
+		limiter, _ := newTestLimiter(Limit{Rate: 100, Burst: 1})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if err := limiter.Take(context.Background(), ModeBlock); err != nil {
+				t.Fatalf("take %d: expected allowed, got %v", i, err)
+			}
+		}
+		// the clock doesn't move, so the second and third tokens are reserved 10ms and 20ms ahead
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("expected waiting for the reserved tokens, waited %s", elapsed)
+		}
+	})
+
+	t.Run("fails if deadline is before token", func(t *testing.T) {
+		limiter, _ := newTestLimiter(Limit{Rate: 1, Burst: 1})
+		_ = limiter.Take(context.Background(), ModeBlock)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := limiter.Take(ctx, ModeBlock); err != ErrRateLimitExceeded {
+			t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("expected failing without waiting, waited %s", elapsed)
+		}
+	})
+
+	t.Run("returns reserved token on cancel", func(t *testing.T) {
+		limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 1})
+		_ = limiter.Take(context.Background(), ModeBlock)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		if err := limiter.Take(ctx, ModeBlock); err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		clock.now = clock.now.Add(time.Second)
+		if err := limiter.Take(context.Background(), ModeFailFast); err != nil {
+			t.Fatalf("expected the cancelled token returned, got %v", err)
+		}
+	})
+}
+
+func TestParseMode(t *testing.T) {
+	testCases := []struct {
+		value   string
+		mode    Mode
+		invalid bool
+	}{
+		{value: "", mode: ModeBlock},
+		{value: "block", mode: ModeBlock},
+		{value: "fail_fast", mode: ModeFailFast},
+		{value: "drop", invalid: true},
+	}
+	for _, tc := range testCases {
+		mode, err := ParseMode(tc.value)
+		if tc.invalid != (err != nil) || mode != tc.mode {
+			t.Errorf("ParseMode(%q) = %q, %v", tc.value, mode, err)
+		}
+	}
+}
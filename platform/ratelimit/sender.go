@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// SenderPlatform limits the emails sent through the wrapped sender platform, e.g. to stay below the provider API key limit.
+type SenderPlatform struct {
+	ID             string
+	SenderPlatform platform.SenderPlatform
+	Limiter        *Limiter
+	Mode           Mode
+}
+
+func NewRateLimitSenderPlatform(id string, senderPlatform platform.SenderPlatform, limiter *Limiter, mode Mode) platform.SenderPlatform {
+	return &SenderPlatform{
+		ID:             id,
+		SenderPlatform: senderPlatform,
+		Limiter:        limiter,
+		Mode:           mode,
+	}
+}
+
+func (e SenderPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e SenderPlatform) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if err := e.take(ctx); err != nil {
+		return nil, err
+	}
+	return platform.SendWithResult(ctx, e.SenderPlatform, e.ID, emailData)
+}
+
+func (e SenderPlatform) MaxBatchSize() int {
+	return platform.MaxBatchSize(e.SenderPlatform)
+}
+
+// SendBatch takes a token per request, since the provider limits the requests rather than the recipients.
+func (e SenderPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	if err := e.take(ctx); err != nil {
+		return nil, err
+	}
+	return platform.SendBatchChunk(ctx, e.SenderPlatform, e.ID, emailData, recipients)
+}
+
+func (e SenderPlatform) take(ctx context.Context) error {
+	err := e.Limiter.Take(ctx, e.Mode)
+	if errors.Is(err, ErrRateLimitExceeded) {
+		return &platform.Error{
+			Provider: e.ID,
+			Message:  err.Error(),
+			Kind:     platform.ErrRateLimited,
+			Err:      err,
+		}
+	}
+	return err
+}
+
+func (e SenderPlatform) CanSendTemplate() bool {
+	return platform.CanSendTemplate(e.SenderPlatform)
+}
+
+func (e SenderPlatform) CanSchedule(sendAt time.Time) bool {
+	return platform.CanSchedule(e.SenderPlatform, sendAt)
+}
+
+func (e SenderPlatform) CancelScheduled(ctx context.Context, scheduleID string) error {
+	return platform.CancelScheduled(ctx, e.SenderPlatform, scheduleID)
+}
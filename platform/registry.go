@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var ErrPlatformNotRegistered = errors.New("sender platform is not registered")
+
+// Config is the generic sender platform configuration, e.g. {"api_key": "..."}.
+// The supported keys are defined by each platform.
+type Config map[string]string
+
+// Factory builds a SenderPlatform from the given configuration.
+type Factory func(config Config) (SenderPlatform, error)
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]Factory{}
+)
+
+// Register makes a sender platform available by the given id.
+// The built-in platforms register themselves on init, an in-house platform could be registered the same way.
+// Registering the same id twice replaces the previous factory.
+func Register(id string, factory Factory) {
+	if factory == nil {
+		panic("platform: Register factory is nil for " + id)
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[id] = factory
+}
+
+// New builds the sender platform registered by the given id.
+func New(id string, config Config) (SenderPlatform, error) {
+	registryLock.RLock()
+	factory, ok := registry[id]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlatformNotRegistered, id)
+	}
+	return factory(config)
+}
+
+// Registered returns the sorted ids of the registered sender platforms.
+func Registered() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (c Config) Get(key string) string {
+	return c[key]
+}
+
+// GetRequired returns the value of the key, or error if it's empty.
+func (c Config) GetRequired(key string) (string, error) {
+	value := c[key]
+	if value == "" {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	return value, nil
+}
+
+// GetInt returns the value of the key as integer, or defaultValue if it's empty.
+func (c Config) GetInt(key string, defaultValue int) (int, error) {
+	value := c[key]
+	if value == "" {
+		return defaultValue, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s value must be an integer", key)
+	}
+	return result, nil
+}
+
+// GetBool returns the value of the key as boolean, or defaultValue if it's empty.
+func (c Config) GetBool(key string, defaultValue bool) (bool, error) {
+	value := c[key]
+	if value == "" {
+		return defaultValue, nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s value must be a boolean", key)
+	}
+	return result, nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+type configPlatform struct {
+	config Config
+}
+
+func (p *configPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	return nil
+}
+
+// withRegistry replaces the registry for the test, so the platforms registered on init are not affected.
+func withRegistry(t *testing.T) {
+	registryLock.Lock()
+	previous := registry
+	registry = map[string]Factory{}
+	registryLock.Unlock()
+	t.Cleanup(func() {
+		registryLock.Lock()
+		registry = previous
+		registryLock.Unlock()
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	withRegistry(t)
+	factoryErr := errors.New("api_key is not set")
+	Register("inhouse", func(config Config) (SenderPlatform, error) {
+		return &configPlatform{config: config}, nil
+	})
+	Register("broken", func(config Config) (SenderPlatform, error) {
+		return nil, factoryErr
+	})
+
+	if ids := Registered(); !reflect.DeepEqual(ids, []string{"broken", "inhouse"}) {
+		t.Errorf("expected sorted ids, got %v", ids)
+	}
+
+	senderPlatform, err := New("inhouse", Config{"api_key": "key-123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := senderPlatform.(*configPlatform).config; config.Get("api_key") != "key-123" {
+		t.Errorf("expected the config passed to the factory, got %v", config)
+	}
+
+	if _, err = New("broken", Config{}); !errors.Is(err, factoryErr) {
+		t.Errorf("expected the factory error, got %v", err)
+	}
+	if _, err = New("unknown", Config{}); !errors.Is(err, ErrPlatformNotRegistered) {
+		t.Errorf("expected ErrPlatformNotRegistered, got %v", err)
+	}
+
+	// registering the same id again replaces the factory
+	Register("broken", func(config Config) (SenderPlatform, error) {
+		return &configPlatform{config: config}, nil
+	})
+	if _, err = New("broken", Config{}); err != nil {
+		t.Errorf("expected the replaced factory, got %v", err)
+	}
+	if ids := Registered(); len(ids) != 2 {
+		t.Errorf("expected 2 ids, got %v", ids)
+	}
+}
+
+func TestRegister_NilFactory(t *testing.T) {
+	withRegistry(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	Register("inhouse", nil)
+}
+
+func TestConfig(t *testing.T) {
+	config := Config{
+		"api_key":     "key-123",
+		"port":        "587",
+		"bad_port":    "smtp",
+		"insecure":    "true",
+		"bad_bool":    "sometimes",
+		"empty_value": "",
+	}
+
+	testCases := []struct {
+		name          string
+		get           func() (interface{}, error)
+		expectedValue interface{}
+		expectErr     bool
+	}{
+		{
+			name:          "get missing",
+			get:           func() (interface{}, error) { return config.Get("missing"), nil },
+			expectedValue: "",
+		},
+		{
+			name:          "required",
+			get:           func() (interface{}, error) { return config.GetRequired("api_key") },
+			expectedValue: "key-123",
+		},
+		{
+			name:          "required empty",
+			get:           func() (interface{}, error) { return config.GetRequired("empty_value") },
+			expectedValue: "",
+			expectErr:     true,
+		},
+		{
+			name:          "int",
+			get:           func() (interface{}, error) { return config.GetInt("port", 25) },
+			expectedValue: 587,
+		},
+		{
+			name:          "int default",
+			get:           func() (interface{}, error) { return config.GetInt("missing", 25) },
+			expectedValue: 25,
+		},
+		{
+			name:          "int invalid",
+			get:           func() (interface{}, error) { return config.GetInt("bad_port", 25) },
+			expectedValue: 0,
+			expectErr:     true,
+		},
+		{
+			name:          "bool",
+			get:           func() (interface{}, error) { return config.GetBool("insecure", false) },
+			expectedValue: true,
+		},
+		{
+			name:          "bool default",
+			get:           func() (interface{}, error) { return config.GetBool("missing", true) },
+			expectedValue: true,
+		},
+		{
+			name:          "bool invalid",
+			get:           func() (interface{}, error) { return config.GetBool("bad_bool", true) },
+			expectedValue: false,
+			expectErr:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			value, err := testCase.get()
+			if (err != nil) != testCase.expectErr {
+				t.Errorf("expected error %v, got %v", testCase.expectErr, err)
+			}
+			if value != testCase.expectedValue {
+				t.Errorf("expected value %v, got %v", testCase.expectedValue, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+// SendResult is the outcome of a successful send.
+type SendResult struct {
+	// Provider is the sender platform id, e.g. "sendgrid".
+	Provider string
+	// MessageID is the id assigned by the provider, e.g. SendGrid's X-Message-Id or Mandrill's _id.
+	// It could be empty if the provider doesn't return one.
+	MessageID          string
+	AcceptedRecipients []string
+	RejectedRecipients []string
+	// SubmittedAt is the time the email is submitted to the provider.
+	SubmittedAt time.Time
+	// CompletedAt is the time the provider accepted the email.
+	CompletedAt time.Time
+	// ScheduleID identifies the scheduled email to cancel it, set if EmailData.SendAt is in the future.
+	ScheduleID string
+}
+
+// ResultSenderPlatform is implemented by the sender platforms able to report the provider message id.
+type ResultSenderPlatform interface {
+	SenderPlatform
+	SendWithResult(ctx context.Context, emailData object.EmailData) (*SendResult, error)
+}
+
+// NewSendResult creates result of the given provider, submitted now.
+func NewSendResult(provider string) *SendResult {
+	return &SendResult{
+		Provider:    provider,
+		SubmittedAt: time.Now(),
+	}
+}
+
+// Complete marks the result as completed now.
+// If the provider doesn't report the accepted recipients, all the email recipients are considered accepted.
+func (r *SendResult) Complete(emailData object.EmailData) *SendResult {
+	r.CompletedAt = time.Now()
+	if r.AcceptedRecipients == nil && r.RejectedRecipients == nil {
+		r.AcceptedRecipients = emailData.GetRecipients()
+	}
+	return r
+}
+
+// SendWithResult sends the email through the sender platform, and returns the provider result if it's supported.
+// For the platforms that only implement SenderPlatform, the result doesn't have the provider message id.
+// The provider is used when the platform doesn't report it, e.g. for the decorators wrapping a custom platform.
+func SendWithResult(ctx context.Context, senderPlatform SenderPlatform, provider string, emailData object.EmailData) (*SendResult, error) {
+	if resultSenderPlatform, ok := senderPlatform.(ResultSenderPlatform); ok {
+		result, err := resultSenderPlatform.SendWithResult(ctx, emailData)
+		if result != nil && result.Provider == "" {
+			result.Provider = provider
+		}
+		return result, err
+	}
+	result := NewSendResult(provider)
+	if err := senderPlatform.Send(ctx, emailData); err != nil {
+		return nil, err
+	}
+	return result.Complete(emailData), nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = 200 * time.Millisecond
+	DefaultMaxBackoff     = 5 * time.Second
+)
+
+var (
+	randomLock sync.Mutex
+	random     = rand.New(rand.NewSource(time.Now().UnixNano())) // nolint: gosec
+)
+
+// SenderPlatform retries the wrapped sender platform on the errors classified as retryable,
+// with jittered exponential backoff or the delay requested by the provider through Retry-After.
+// If Retry-After is longer than MaxBackoff, the error is returned instead of waiting, so the caller decides when to retry.
+type SenderPlatform struct {
+	SenderPlatform platform.SenderPlatform
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func NewRetrySenderPlatform(senderPlatform platform.SenderPlatform, maxAttempts int, initialBackoff, maxBackoff time.Duration) platform.SenderPlatform {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultInitialBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = DefaultMaxBackoff
+		if maxBackoff < initialBackoff {
+			maxBackoff = initialBackoff
+		}
+	}
+	return &SenderPlatform{
+		SenderPlatform: senderPlatform,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}
+}
+
+func (e SenderPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e SenderPlatform) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	return e.retry(ctx, emailData, func() (*platform.SendResult, error) {
+		return platform.SendWithResult(ctx, e.SenderPlatform, "", emailData)
+	})
+}
+
+func (e SenderPlatform) MaxBatchSize() int {
+	return platform.MaxBatchSize(e.SenderPlatform)
+}
+
+func (e SenderPlatform) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	return e.retry(ctx, emailData, func() (*platform.SendResult, error) {
+		return platform.SendBatchChunk(ctx, e.SenderPlatform, "", emailData, recipients)
+	})
+}
+
+func (e SenderPlatform) retry(ctx context.Context, emailData object.EmailData, send func() (*platform.SendResult, error)) (*platform.SendResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := send()
+		if err == nil || attempt >= e.MaxAttempts || !platform.IsRetryable(err) {
+			return result, err
+		}
+
+		retryAfter := platform.GetRetryAfter(err)
+		if retryAfter > e.MaxBackoff {
+			return result, err
+		}
+		delay := e.getBackoff(attempt, retryAfter)
+		// don't wait if the caller would give up before the next attempt
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+		logrus.Warnf("Retrying send email to %s in %s (attempt %d/%d): %s", emailData.To, delay, attempt+1, e.MaxAttempts, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// getBackoff returns the delay before the next attempt.
+// The Retry-After delay, at most MaxBackoff, is honoured as is, otherwise the delay is randomized
+// between half and the full exponential backoff, capped at MaxBackoff.
+func (e SenderPlatform) getBackoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	backoff := e.InitialBackoff
+	for i := 1; i < attempt && backoff < e.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > e.MaxBackoff {
+		backoff = e.MaxBackoff
+	}
+
+	randomLock.Lock()
+	jitter := time.Duration(random.Int63n(int64(backoff/2) + 1))
+	randomLock.Unlock()
+	return backoff/2 + jitter
+}
+
+func (e SenderPlatform) CanSendTemplate() bool {
+	return platform.CanSendTemplate(e.SenderPlatform)
+}
+
+func (e SenderPlatform) CanSchedule(sendAt time.Time) bool {
+	return platform.CanSchedule(e.SenderPlatform, sendAt)
+}
+
+func (e SenderPlatform) CancelScheduled(ctx context.Context, scheduleID string) error {
+	return platform.CancelScheduled(ctx, e.SenderPlatform, scheduleID)
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// fakePlatform returns the errors in order, then succeeds.
+type fakePlatform struct {
+	errs  []error
+	calls int
+}
+
+func (p *fakePlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return p.errs[p.calls-1]
+	}
+	return nil
+}
+
+func TestSenderPlatform_SendWithResult(t *testing.T) {
+	unavailableErr := &platform.Error{Provider: "fake", Kind: platform.ErrProviderUnavailable, Retryable: true}
+	badRequestErr := &platform.Error{Provider: "fake", Kind: platform.ErrBadRequest}
+	retryAfterErr := &platform.Error{Provider: "fake", Kind: platform.ErrRateLimited, Retryable: true, RetryAfter: 50 * time.Millisecond}
+	unclassifiedErr := errors.New("unexpected error")
+	longRetryAfterErr := &platform.Error{Provider: "fake", Kind: platform.ErrRateLimited, Retryable: true, RetryAfter: time.Minute}
+
+	testCases := []struct {
+		name            string
+		errs            []error
+		ctxTimeout      time.Duration
+		expectedErr     error
+		expectedCalls   int
+		expectedMinWait time.Duration
+		expectedMaxWait time.Duration
+	}{
+		{
+			name:          "succeeds on the first attempt",
+			expectedCalls: 1,
+		},
+		{
+			name:          "retries retryable error",
+			errs:          []error{unavailableErr, unavailableErr},
+			expectedCalls: 3,
+		},
+		{
+			name:          "gives up after max attempts",
+			errs:          []error{unavailableErr, unavailableErr, unavailableErr, unavailableErr},
+			expectedErr:   unavailableErr,
+			expectedCalls: 3,
+		},
+		{
+			name:          "doesn't retry non retryable error",
+			errs:          []error{badRequestErr},
+			expectedErr:   badRequestErr,
+			expectedCalls: 1,
+		},
+		{
+			name:          "doesn't retry unclassified error",
+			errs:          []error{unclassifiedErr},
+			expectedErr:   unclassifiedErr,
+			expectedCalls: 1,
+		},
+		{
+			name:            "waits for Retry-After",
+			errs:            []error{retryAfterErr},
+			expectedCalls:   2,
+			expectedMinWait: 50 * time.Millisecond,
+		},
+		{
+			name:            "returns Retry-After longer than max backoff without waiting",
+			errs:            []error{longRetryAfterErr},
+			expectedErr:     longRetryAfterErr,
+			expectedCalls:   1,
+			expectedMaxWait: 50 * time.Millisecond,
+		},
+		{
+			name:            "doesn't wait past the context deadline",
+			errs:            []error{retryAfterErr},
+			ctxTimeout:      20 * time.Millisecond,
+			expectedErr:     retryAfterErr,
+			expectedCalls:   1,
+			expectedMaxWait: 20 * time.Millisecond,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fake := &fakePlatform{errs: testCase.errs}
+			senderPlatform := NewRetrySenderPlatform(fake, 3, time.Millisecond, 100*time.Millisecond).(*SenderPlatform)
+			ctx := context.Background()
+			if testCase.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, testCase.ctxTimeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			result, err := senderPlatform.SendWithResult(ctx, object.EmailData{To: "player@example.com"})
+			elapsed := time.Since(start)
+
+			if err != testCase.expectedErr {
+				t.Errorf("expected error %v, got %v", testCase.expectedErr, err)
+			}
+			if err == nil && result == nil {
+				t.Error("expected result")
+			}
+			if fake.calls != testCase.expectedCalls {
+				t.Errorf("expected %d attempts, got %d", testCase.expectedCalls, fake.calls)
+			}
+			if elapsed < testCase.expectedMinWait {
+				t.Errorf("expected waiting at least %s, got %s", testCase.expectedMinWait, elapsed)
+			}
+			if testCase.expectedMaxWait > 0 && elapsed >= testCase.expectedMaxWait {
+				t.Errorf("expected waiting less than %s, got %s", testCase.expectedMaxWait, elapsed)
+			}
+		})
+	}
+}
+
+func TestSenderPlatform_ContextCancelled(t *testing.T) {
+	unavailableErr := &platform.Error{Provider: "fake", Kind: platform.ErrProviderUnavailable, Retryable: true}
+	fake := &fakePlatform{errs: []error{unavailableErr, unavailableErr}}
+	senderPlatform := NewRetrySenderPlatform(fake, 3, time.Second, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if err := senderPlatform.Send(ctx, object.EmailData{To: "player@example.com"}); err != unavailableErr {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("expected the wait interrupted, got %s", elapsed)
+	}
+	if fake.calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", fake.calls)
+	}
+}
+
+func TestSenderPlatform_GetBackoff(t *testing.T) {
+	senderPlatform := SenderPlatform{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	testCases := []struct {
+		attempt     int
+		retryAfter  time.Duration
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{attempt: 1, expectedMin: 50 * time.Millisecond, expectedMax: 100 * time.Millisecond},
+		{attempt: 2, expectedMin: 100 * time.Millisecond, expectedMax: 200 * time.Millisecond},
+		{attempt: 3, expectedMin: 200 * time.Millisecond, expectedMax: 400 * time.Millisecond},
+		{attempt: 10, expectedMin: 500 * time.Millisecond, expectedMax: time.Second},
+		{attempt: 1, retryAfter: 700 * time.Millisecond, expectedMin: 700 * time.Millisecond, expectedMax: 700 * time.Millisecond},
+	}
+
+	for _, testCase := range testCases {
+		for i := 0; i < 20; i++ {
+			backoff := senderPlatform.getBackoff(testCase.attempt, testCase.retryAfter)
+			if backoff < testCase.expectedMin || backoff > testCase.expectedMax {
+				t.Errorf("attempt %d: expected backoff between %s and %s, got %s",
+					testCase.attempt, testCase.expectedMin, testCase.expectedMax, backoff)
+				break
+			}
+		}
+	}
+}
+
+func TestNewRetrySenderPlatform_Defaults(t *testing.T) {
+	senderPlatform := NewRetrySenderPlatform(&fakePlatform{}, 0, 0, 0).(*SenderPlatform)
+	if senderPlatform.MaxAttempts != DefaultMaxAttempts || senderPlatform.InitialBackoff != DefaultInitialBackoff ||
+		senderPlatform.MaxBackoff != DefaultMaxBackoff {
+		t.Errorf("expected the defaults, got %+v", senderPlatform)
+	}
+
+	// the max backoff is never shorter than the initial backoff
+	senderPlatform = NewRetrySenderPlatform(&fakePlatform{}, 5, 10*time.Second, time.Second).(*SenderPlatform)
+	if senderPlatform.MaxBackoff != 10*time.Second {
+		t.Errorf("expected max backoff 10s, got %s", senderPlatform.MaxBackoff)
+	}
+}
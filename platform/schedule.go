@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSchedulingNotSupported = errors.New("sender platform doesn't support scheduled sending")
+
+// ScheduledSenderPlatform is implemented by the sender platforms able to schedule the email natively,
+// sending it at EmailData.SendAt. The schedule id is returned as SendResult.ScheduleID.
+type ScheduledSenderPlatform interface {
+	SenderPlatform
+	// CanSchedule tells whether the email could be scheduled at sendAt, e.g. SendGrid only schedules up to 72 hours ahead.
+	CanSchedule(sendAt time.Time) bool
+	CancelScheduled(ctx context.Context, scheduleID string) error
+}
+
+// CanSchedule returns true if the sender platform schedules the email at sendAt natively.
+func CanSchedule(senderPlatform SenderPlatform, sendAt time.Time) bool {
+	if scheduledSenderPlatform, ok := senderPlatform.(ScheduledSenderPlatform); ok {
+		return scheduledSenderPlatform.CanSchedule(sendAt)
+	}
+	return false
+}
+
+// CancelScheduled cancels the email scheduled by the sender platform.
+func CancelScheduled(ctx context.Context, senderPlatform SenderPlatform, scheduleID string) error {
+	if scheduledSenderPlatform, ok := senderPlatform.(ScheduledSenderPlatform); ok {
+		return scheduledSenderPlatform.CancelScheduled(ctx, scheduleID)
+	}
+	return ErrSchedulingNotSupported
+}
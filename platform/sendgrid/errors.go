@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package sendgrid
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+// recipientFieldRegex matches the error field of an invalid recipient, e.g. "personalizations.0.to.1.email"
+var recipientFieldRegex = regexp.MustCompile(`^personalizations\.(\d+)\.(to|cc|bcc)\.(\d+)\.email$`)
+
+type errorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Field   string `json:"field"`
+	} `json:"errors"`
+}
+
+// newError parses SendGrid errors[] response into platform error.
+func newError(resp *http.Response, responseBody []byte, payload *emailPayload) *platform.Error {
+	platformErr := platform.NewHTTPError(PlatformID, resp.StatusCode, resp.Header, string(responseBody))
+
+	response := &errorResponse{}
+	if err := json.Unmarshal(responseBody, response); err != nil || len(response.Errors) == 0 {
+		return platformErr
+	}
+
+	messages := make([]string, 0, len(response.Errors))
+	for _, responseErr := range response.Errors {
+		messages = append(messages, responseErr.Message)
+		if recipient, ok := getRecipientOfField(responseErr.Field, payload); ok {
+			platformErr.Kind = platform.ErrInvalidRecipient
+			platformErr.Recipients = append(platformErr.Recipients, recipient)
+		}
+	}
+	platformErr.Message = strings.Join(messages, "; ")
+	platformErr.Code = response.Errors[0].Field
+	return platformErr
+}
+
+func getRecipientOfField(field string, payload *emailPayload) (string, bool) {
+	matches := recipientFieldRegex.FindStringSubmatch(field)
+	if matches == nil || payload == nil {
+		return "", false
+	}
+	personalizationIndex, _ := strconv.Atoi(matches[1])
+	recipientIndex, _ := strconv.Atoi(matches[3])
+	if personalizationIndex >= len(payload.Personalizations) {
+		return "", false
+	}
+
+	var recipients []mail
+	switch matches[2] {
+	case "to":
+		recipients = payload.Personalizations[personalizationIndex].To
+	case "cc":
+		recipients = payload.Personalizations[personalizationIndex].CC
+	case "bcc":
+		recipients = payload.Personalizations[personalizationIndex].BCC
+	}
+	if recipientIndex >= len(recipients) {
+		return "", false
+	}
+	return recipients[recipientIndex].Email, true
+}
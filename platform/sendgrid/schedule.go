@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package sendgrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+const (
+	createBatchPath    = "/v3/mail/batch"
+	scheduledSendsPath = "/v3/user/scheduled_sends"
+
+	// MaxScheduleAhead is the furthest time SendGrid could schedule the email.
+	MaxScheduleAhead = 72 * time.Hour
+)
+
+type batch struct {
+	BatchID string `json:"batch_id"`
+	Status  string `json:"status,omitempty"`
+}
+
+func (e MailSender) CanSchedule(sendAt time.Time) bool {
+	return time.Until(sendAt) < MaxScheduleAhead
+}
+
+// CancelScheduled cancels the scheduled email by its batch id.
+func (e MailSender) CancelScheduled(ctx context.Context, scheduleID string) error {
+	_, err := e.doJSONRequest(ctx, scheduledSendsPath, &batch{BatchID: scheduleID, Status: "cancel"})
+	return err
+}
+
+func (e MailSender) createBatchID(ctx context.Context) (string, error) {
+	responseBody, err := e.doJSONRequest(ctx, createBatchPath, nil)
+	if err != nil {
+		return "", err
+	}
+	response := &batch{}
+	if err = json.Unmarshal(responseBody, response); err != nil {
+		return "", err
+	}
+	return response.BatchID, nil
+}
+
+func (e MailSender) doJSONRequest(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	var payloadBytes []byte
+	if payload != nil {
+		var err error
+		if payloadBytes, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := &http.Client{
+		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, platform.NewTransportError(PlatformID, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, newError(resp, responseBody, nil)
+	}
+	return responseBody, nil
+}
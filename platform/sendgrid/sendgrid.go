@@ -19,8 +19,8 @@ package sendgrid
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -35,10 +35,20 @@ import (
 const (
 	PlatformID = "sendgrid"
 
+	ConfigKeyAPIKey          = "api_key"
+	ConfigKeyEmailCategories = "email_categories"
+
+	// MaxPersonalizations is the max personalizations, i.e. the batch recipients, of a request.
+	MaxPersonalizations = 1000
+
 	apiHost       = "https://api.sendgrid.com"
 	sendEmailPath = "/v3/mail/send"
 )
 
+func init() {
+	platform.Register(PlatformID, NewSendGridClientFromConfig)
+}
+
 type MailSender struct {
 	Host                   string
 	APIKey                 string
@@ -51,9 +61,11 @@ type mail struct {
 }
 
 type attachment struct {
-	Content  string `json:"content"`
-	Types    string `json:"type"`
-	Filename string `json:"filename"`
+	Content     string `json:"content"`
+	Types       string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition,omitempty"`
+	ContentID   string `json:"content_id,omitempty"`
 }
 
 type emailPayload struct {
@@ -61,14 +73,24 @@ type emailPayload struct {
 	From             mail              `json:"from"`
 	ReplyTo          *mail             `json:"reply_to,omitempty"`
 	Personalizations []personalization `json:"personalizations"`
-	TemplateID       string            `json:"template_id"`
-	Attachments      []attachment      `json:"attachments"`
+	TemplateID       string            `json:"template_id,omitempty"`
+	Content          []content         `json:"content,omitempty"`
+	Attachments      []attachment      `json:"attachments,omitempty"`
 	Categories       []string          `json:"categories,omitempty"`
+	SendAt           int64             `json:"send_at,omitempty"`
+	BatchID          string            `json:"batch_id,omitempty"`
+	CustomArgs       map[string]string `json:"custom_args,omitempty"`
+}
+
+type content struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
 type personalization struct {
 	To                  []mail                 `json:"to"`
 	CC                  []mail                 `json:"cc,omitempty"`
+	BCC                 []mail                 `json:"bcc,omitempty"`
 	DynamicTemplateData map[string]interface{} `json:"dynamic_template_data"`
 }
 
@@ -80,14 +102,24 @@ func NewSendGridClient(apiKey, emailCategories string) platform.SenderPlatform {
 	}
 }
 
-func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
-	// set default email categories
-	var emailCategories []string
-	if e.DefaultEmailCategories != "" {
-		emailCategories = strings.Split(e.DefaultEmailCategories, ",")
+// NewSendGridClientFromConfig creates SendGrid sender platform from the generic platform configuration.
+func NewSendGridClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	apiKey, err := config.GetRequired(ConfigKeyAPIKey)
+	if err != nil {
+		return nil, err
 	}
-	if len(emailData.Categories) > 0 {
-		emailCategories = append(emailCategories, emailData.Categories...)
+	return NewSendGridClient(apiKey, config.Get(ConfigKeyEmailCategories)), nil
+}
+
+func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	tos := make([]mail, 0)
+	for _, toAddress := range emailData.GetToAddresses() {
+		tos = append(tos, mail{Email: toAddress.Address, Name: toAddress.Name})
 	}
 
 	CCs := make([]mail, 0)
@@ -95,28 +127,94 @@ func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error
 		CCs = append(CCs, mail{Email: ccEmailData})
 	}
 
+	BCCs := make([]mail, 0)
+	for _, bccAddress := range emailData.Bcc {
+		BCCs = append(BCCs, mail{Email: bccAddress.Address, Name: bccAddress.Name})
+	}
+
 	personalizations := []personalization{
 		{
-			To:                  []mail{{Email: emailData.To}},
+			To:                  tos,
 			CC:                  CCs,
+			BCC:                 BCCs,
 			DynamicTemplateData: emailData.XMCMergeVars,
 		},
 	}
 
+	result, err := e.send(ctx, emailData, personalizations)
+	if err != nil {
+		return nil, err
+	}
+	return result.Complete(emailData), nil
+}
+
+// MaxBatchSize returns the max personalizations of a request.
+func (e MailSender) MaxBatchSize() int {
+	return MaxPersonalizations
+}
+
+// SendBatch sends the email to the recipients in a request, a personalization per recipient with its own merge vars.
+func (e MailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) (*platform.SendResult, error) {
+	personalizations := make([]personalization, 0, len(recipients))
+	for _, recipient := range recipients {
+		personalizations = append(personalizations, personalization{
+			To:                  []mail{{Email: recipient.Address.Address, Name: recipient.Address.Name}},
+			DynamicTemplateData: recipient.GetMergeVars(emailData),
+		})
+	}
+	result, err := e.send(ctx, emailData, personalizations)
+	if err != nil {
+		return nil, err
+	}
+	result.AcceptedRecipients = object.GetRecipientAddresses(recipients)
+	return result.Complete(emailData), nil
+}
+
+func (e MailSender) send(ctx context.Context, emailData object.EmailData, personalizations []personalization) (*platform.SendResult, error) {
+	// set default email categories
+	var emailCategories []string
+	if e.DefaultEmailCategories != "" {
+		emailCategories = strings.Split(e.DefaultEmailCategories, ",")
+	}
+	if len(emailData.Categories) > 0 {
+		emailCategories = append(emailCategories, emailData.Categories...)
+	}
+
+	attachments, err := convertToAttachments(emailData.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := &emailPayload{
 		Subject:          emailData.Subject,
 		From:             mail{Email: emailData.From, Name: emailData.FromName},
 		Personalizations: personalizations,
 		TemplateID:       emailData.XMCTemplate,
+		Attachments:      attachments,
 		Categories:       emailCategories,
 	}
+	if emailData.XMCTemplate == "" {
+		payload.Content = convertToContent(emailData)
+	}
 	if emailData.ReplyTo != "" {
 		payload.ReplyTo = &mail{Email: emailData.ReplyTo}
 	}
+	if emailData.Namespace != "" {
+		payload.CustomArgs = map[string]string{constant.NamespaceMetadataKey: emailData.Namespace}
+	}
+	if emailData.IsScheduled() {
+		// the batch id is required to cancel the scheduled email
+		payload.BatchID, err = e.createBatchID(ctx)
+		if err != nil {
+			logrus.Errorf("Error send email to %s using sendgrid: %s", emailData.To, err)
+			return nil, err
+		}
+		payload.SendAt = emailData.SendAt.Unix()
+	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	body := bytes.NewBuffer(payloadBytes)
 
@@ -125,7 +223,7 @@ func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error
 	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Host+sendEmailPath, body)
 	if err != nil {
 		logrus.Errorf("Error send email to %s using sendgrid: %s", emailData.To, err)
-		return err
+		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+e.APIKey)
 	req.Header.Set("Content-Type", "application/json")
@@ -133,10 +231,11 @@ func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error
 	httpClient := &http.Client{
 		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
 	}
+	result := platform.NewSendResult(PlatformID)
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		logrus.Errorf("Error send email to %s using sendgrid: %s", emailData.To, err)
-		return err
+		return nil, platform.NewTransportError(PlatformID, err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -145,10 +244,42 @@ func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error
 	if resp.StatusCode != http.StatusAccepted {
 		errorsResponseBody, errReadResp := ioutil.ReadAll(resp.Body)
 		if errReadResp != nil {
-			return errReadResp
+			return nil, errReadResp
 		}
 		logrus.Errorf("Error send email to %s using sendgrid: %s", emailData.To, string(errorsResponseBody))
-		return errors.New(string(errorsResponseBody))
+		return nil, newError(resp, errorsResponseBody, payload)
+	}
+	result.MessageID = resp.Header.Get("X-Message-Id")
+	result.ScheduleID = payload.BatchID
+	return result, nil
+}
+
+// convertToContent returns the content of the email without template, SendGrid requires the plain text to come first.
+func convertToContent(emailData object.EmailData) []content {
+	var contents []content
+	if emailData.TextBody != "" {
+		contents = append(contents, content{Type: "text/plain", Value: emailData.TextBody})
+	}
+	if emailData.HTMLBody != "" {
+		contents = append(contents, content{Type: "text/html", Value: emailData.HTMLBody})
+	}
+	return contents
+}
+
+func convertToAttachments(emailAttachments []object.Attachment) ([]attachment, error) {
+	attachments := make([]attachment, 0, len(emailAttachments))
+	for i := range emailAttachments {
+		content, err := emailAttachments[i].GetContent()
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment{
+			Content:     base64.StdEncoding.EncodeToString(content),
+			Types:       emailAttachments[i].GetContentType(),
+			Filename:    emailAttachments[i].Filename,
+			Disposition: emailAttachments[i].Disposition,
+			ContentID:   emailAttachments[i].ContentID,
+		})
 	}
-	return nil
+	return attachments, nil
 }
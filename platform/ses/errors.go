@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package ses
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// newError parses SES error response into platform error, the error code is read from the X-Amzn-ErrorType header.
+func newError(resp *http.Response, responseBody []byte) *platform.Error {
+	platformErr := platform.NewHTTPError(PlatformID, resp.StatusCode, resp.Header, string(responseBody))
+
+	response := &errorResponse{}
+	if err := json.Unmarshal(responseBody, response); err == nil && response.Message != "" {
+		platformErr.Message = response.Message
+	}
+	// e.g. "MessageRejected:http://internal.amazon.com/coral/com.amazonaws.sesv2/"
+	platformErr.Code = strings.SplitN(resp.Header.Get("X-Amzn-ErrorType"), ":", 2)[0]
+
+	switch platformErr.Code {
+	case "TooManyRequestsException":
+		platformErr.Kind = platform.ErrRateLimited
+		platformErr.Retryable = true
+	case "LimitExceededException":
+		// the sending quota is exceeded, retrying shortly won't help
+		platformErr.Kind = platform.ErrRateLimited
+		platformErr.Retryable = false
+	case "AccountSuspendedException", "SendingPausedException", "MailFromDomainNotVerifiedException":
+		platformErr.Kind = platform.ErrUnauthorized
+		platformErr.Retryable = false
+	case "MessageRejected", "BadRequestException", "NotFoundException":
+		platformErr.Kind = platform.ErrBadRequest
+		platformErr.Retryable = false
+	}
+	return platformErr
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package ses
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PlatformID = "ses"
+
+	ConfigKeyEndpoint         = "endpoint"
+	ConfigKeyRegion           = "region"
+	ConfigKeyAccessKeyID      = "access_key_id"
+	ConfigKeySecretAccessKey  = "secret_access_key"
+	ConfigKeySessionToken     = "session_token"
+	ConfigKeyConfigurationSet = "configuration_set"
+
+	defaultEndpointFormat = "https://email.%s.amazonaws.com"
+	sendEmailPath         = "/v2/email/outbound-emails"
+)
+
+func init() {
+	platform.Register(PlatformID, NewSESClientFromConfig)
+}
+
+type MailSender struct {
+	Endpoint         string
+	Region           string
+	AccessKeyID      string
+	SecretAccessKey  string
+	SessionToken     string
+	ConfigurationSet string
+}
+
+type destination struct {
+	ToAddresses  []string `json:"ToAddresses"`
+	CcAddresses  []string `json:"CcAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+type attachment struct {
+	FileName                string `json:"FileName"`
+	RawContent              string `json:"RawContent"`
+	ContentType             string `json:"ContentType,omitempty"`
+	ContentDisposition      string `json:"ContentDisposition,omitempty"`
+	ContentID               string `json:"ContentId,omitempty"`
+	ContentTransferEncoding string `json:"ContentTransferEncoding,omitempty"`
+}
+
+type templateContent struct {
+	TemplateName string       `json:"TemplateName"`
+	TemplateData string       `json:"TemplateData"`
+	Attachments  []attachment `json:"Attachments,omitempty"`
+}
+
+type contentData struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset,omitempty"`
+}
+
+type body struct {
+	Text *contentData `json:"Text,omitempty"`
+	HTML *contentData `json:"Html,omitempty"`
+}
+
+type simpleContent struct {
+	Subject     contentData  `json:"Subject"`
+	Body        body         `json:"Body"`
+	Attachments []attachment `json:"Attachments,omitempty"`
+}
+
+type emailContent struct {
+	Template *templateContent `json:"Template,omitempty"`
+	Simple   *simpleContent   `json:"Simple,omitempty"`
+}
+
+type emailTag struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sendResponse struct {
+	MessageID string `json:"MessageId"`
+}
+
+type emailPayload struct {
+	FromEmailAddress     string       `json:"FromEmailAddress"`
+	Destination          destination  `json:"Destination"`
+	ReplyToAddresses     []string     `json:"ReplyToAddresses,omitempty"`
+	Content              emailContent `json:"Content"`
+	EmailTags            []emailTag   `json:"EmailTags,omitempty"`
+	ConfigurationSetName string       `json:"ConfigurationSetName,omitempty"`
+}
+
+// NewSESClient creates SES v2 sender platform.
+// If endpoint is empty, the public SES endpoint of the given region is used.
+func NewSESClient(endpoint, region, accessKeyID, secretAccessKey, sessionToken, configurationSet string) platform.SenderPlatform {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf(defaultEndpointFormat, region)
+	}
+	return &MailSender{
+		Endpoint:         endpoint,
+		Region:           region,
+		AccessKeyID:      accessKeyID,
+		SecretAccessKey:  secretAccessKey,
+		SessionToken:     sessionToken,
+		ConfigurationSet: configurationSet,
+	}
+}
+
+// NewSESClientFromConfig creates SES v2 sender platform from the generic platform configuration.
+func NewSESClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	region, err := config.GetRequired(ConfigKeyRegion)
+	if err != nil {
+		return nil, err
+	}
+	accessKeyID, err := config.GetRequired(ConfigKeyAccessKeyID)
+	if err != nil {
+		return nil, err
+	}
+	secretAccessKey, err := config.GetRequired(ConfigKeySecretAccessKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewSESClient(
+		config.Get(ConfigKeyEndpoint),
+		region,
+		accessKeyID,
+		secretAccessKey,
+		config.Get(ConfigKeySessionToken),
+		config.Get(ConfigKeyConfigurationSet),
+	), nil
+}
+
+func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	from := mail.Address{Address: emailData.From, Name: emailData.FromName}
+
+	payload := &emailPayload{
+		FromEmailAddress: from.String(),
+		Destination: destination{
+			ToAddresses:  formatAddresses(emailData.GetToAddresses()),
+			CcAddresses:  emailData.CarbonCopy,
+			BccAddresses: formatAddresses(emailData.Bcc),
+		},
+		ConfigurationSetName: e.ConfigurationSet,
+	}
+	if emailData.ReplyTo != "" {
+		payload.ReplyToAddresses = []string{emailData.ReplyTo}
+	}
+	for _, category := range emailData.Categories {
+		payload.EmailTags = append(payload.EmailTags, emailTag{Name: "category", Value: category})
+	}
+
+	attachments, err := convertToAttachments(emailData.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailData.XMCTemplate != "" {
+		templateData, err := json.Marshal(emailData.XMCMergeVars)
+		if err != nil {
+			return nil, err
+		}
+		payload.Content.Template = &templateContent{
+			TemplateName: emailData.XMCTemplate,
+			TemplateData: string(templateData),
+			Attachments:  attachments,
+		}
+	} else {
+		payload.Content.Simple = &simpleContent{
+			Subject:     contentData{Data: emailData.Subject, Charset: "UTF-8"},
+			Attachments: attachments,
+		}
+		if emailData.HTMLBody != "" {
+			payload.Content.Simple.Body.HTML = &contentData{Data: emailData.HTMLBody, Charset: "UTF-8"}
+		}
+		if emailData.TextBody != "" || emailData.HTMLBody == "" {
+			payload.Content.Simple.Body.Text = &contentData{Data: emailData.TextBody, Charset: "UTF-8"}
+		}
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+	req, err := http.NewRequestWithContext(subCtx, http.MethodPost, e.Endpoint+sendEmailPath, bytes.NewReader(payloadBytes))
+	if err != nil {
+		logrus.Errorf("Error send email to %s using SES: %s", emailData.To, err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signRequest(req, payloadBytes, e.Region, signingService, e.AccessKeyID, e.SecretAccessKey, e.SessionToken, time.Now())
+
+	httpClient := &http.Client{
+		Timeout: time.Second * constant.DefaultHTTPTimeoutInSeconds,
+	}
+	result := platform.NewSendResult(PlatformID)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using SES: %s", emailData.To, err)
+		return nil, platform.NewTransportError(PlatformID, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		errorsResponseBody, errReadResp := ioutil.ReadAll(resp.Body)
+		if errReadResp != nil {
+			return nil, errReadResp
+		}
+		logrus.Errorf("Error send email to %s using SES: %s", emailData.To, string(errorsResponseBody))
+		return nil, newError(resp, errorsResponseBody)
+	}
+
+	response := &sendResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		logrus.Warnf("Unable to read SES message id of email to %s: %s", emailData.To, err)
+	}
+	result.MessageID = response.MessageID
+	return result.Complete(emailData), nil
+}
+
+func formatAddresses(addresses []mail.Address) []string {
+	var formatted []string
+	for _, address := range addresses {
+		formatted = append(formatted, object.FormatAddress(address))
+	}
+	return formatted
+}
+
+func convertToAttachments(emailAttachments []object.Attachment) ([]attachment, error) {
+	var attachments []attachment
+	for i := range emailAttachments {
+		content, err := emailAttachments[i].GetContent()
+		if err != nil {
+			return nil, err
+		}
+		disposition := "ATTACHMENT"
+		if emailAttachments[i].IsInline() {
+			disposition = "INLINE"
+		}
+		attachments = append(attachments, attachment{
+			FileName:                emailAttachments[i].Filename,
+			RawContent:              base64.StdEncoding.EncodeToString(content),
+			ContentType:             emailAttachments[i].GetContentType(),
+			ContentDisposition:      disposition,
+			ContentID:               emailAttachments[i].ContentID,
+			ContentTransferEncoding: "BASE64",
+		})
+	}
+	return attachments, nil
+}
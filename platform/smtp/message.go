@@ -0,0 +1,341 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+)
+
+const base64LineLength = 76
+
+// Attachment is a file attached to a Message.
+// Inline attachments are referenced from the HTML body through their ContentID (cid:<ContentID>).
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+	Inline      bool
+	ContentID   string
+}
+
+// Message is an RFC 5322 email message.
+type Message struct {
+	From        mail.Address
+	To          []mail.Address
+	Cc          []mail.Address
+	Bcc         []mail.Address
+	ReplyTo     []mail.Address
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	Headers     map[string]string
+	Attachments []Attachment
+	Date        time.Time
+	MessageID   string
+}
+
+// NewMessage builds the message of the email content, with a generated Message-ID.
+// XMCTemplate and XMCMergeVars are not used, the template must be rendered into HTMLBody or TextBody.
+func NewMessage(emailData object.EmailData) (*Message, error) {
+	msg := &Message{
+		From:     mail.Address{Address: emailData.From, Name: emailData.FromName},
+		To:       emailData.GetToAddresses(),
+		Bcc:      emailData.Bcc,
+		Subject:  emailData.Subject,
+		TextBody: emailData.TextBody,
+		HTMLBody: emailData.HTMLBody,
+	}
+	for _, cc := range emailData.CarbonCopy {
+		msg.Cc = append(msg.Cc, mail.Address{Address: cc})
+	}
+	if emailData.ReplyTo != "" {
+		msg.ReplyTo = []mail.Address{{Address: emailData.ReplyTo}}
+	}
+	for i := range emailData.Attachments {
+		content, err := emailData.Attachments[i].GetContent()
+		if err != nil {
+			return nil, err
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename:    emailData.Attachments[i].Filename,
+			ContentType: emailData.Attachments[i].GetContentType(),
+			Content:     content,
+			Inline:      emailData.Attachments[i].IsInline(),
+			ContentID:   emailData.Attachments[i].ContentID,
+		})
+	}
+
+	messageID, err := generateMessageID(msg.From.Address)
+	if err != nil {
+		return nil, err
+	}
+	msg.MessageID = messageID
+	return msg, nil
+}
+
+// Recipients returns the envelope recipients of the message, including Bcc.
+func (m *Message) Recipients() []string {
+	recipients := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	for _, list := range [][]mail.Address{m.To, m.Cc, m.Bcc} {
+		for _, address := range list {
+			recipients = append(recipients, address.Address)
+		}
+	}
+	return recipients
+}
+
+// Bytes builds the MIME encoded message.
+// Bcc recipients are never written to the headers.
+func (m *Message) Bytes() ([]byte, error) {
+	if m.From.Address == "" {
+		return nil, errors.New("message sender is not specified")
+	}
+	if len(m.To)+len(m.Cc)+len(m.Bcc) == 0 {
+		return nil, errors.New("message recipient is not specified")
+	}
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	messageID := m.MessageID
+	if messageID == "" {
+		var err error
+		messageID, err = generateMessageID(m.From.Address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	writeHeader(buf, "From", m.From.String())
+	writeAddressHeader(buf, "To", m.To)
+	writeAddressHeader(buf, "Cc", m.Cc)
+	writeAddressHeader(buf, "Reply-To", m.ReplyTo)
+	writeHeader(buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(buf, "Message-ID", messageID)
+	writeHeader(buf, "MIME-Version", "1.0")
+
+	customHeaderKeys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		customHeaderKeys = append(customHeaderKeys, key)
+	}
+	sort.Strings(customHeaderKeys)
+	for _, key := range customHeaderKeys {
+		writeHeader(buf, textproto.CanonicalMIMEHeaderKey(key), m.Headers[key])
+	}
+
+	contentType, body, err := m.buildBody()
+	if err != nil {
+		return nil, err
+	}
+	contentHeaderKeys := make([]string, 0, len(contentType))
+	for key := range contentType {
+		contentHeaderKeys = append(contentHeaderKeys, key)
+	}
+	sort.Strings(contentHeaderKeys)
+	for _, key := range contentHeaderKeys {
+		writeHeader(buf, key, contentType.Get(key))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// buildBody returns the top level part headers and content, nesting
+// multipart/mixed > multipart/related > multipart/alternative as needed.
+func (m *Message) buildBody() (textproto.MIMEHeader, []byte, error) {
+	var inlines, attachments []Attachment
+	for _, attachment := range m.Attachments {
+		if attachment.Inline {
+			inlines = append(inlines, attachment)
+		} else {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	header, body, err := m.buildAlternativeBody()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(inlines) > 0 {
+		parts := []mimePart{{header: header, body: body}}
+		for _, inline := range inlines {
+			parts = append(parts, attachmentPart(inline))
+		}
+		header, body, err = buildMultipart("related", parts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(attachments) > 0 {
+		parts := []mimePart{{header: header, body: body}}
+		for _, attachment := range attachments {
+			parts = append(parts, attachmentPart(attachment))
+		}
+		header, body, err = buildMultipart("mixed", parts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return header, body, nil
+}
+
+func (m *Message) buildAlternativeBody() (textproto.MIMEHeader, []byte, error) {
+	var parts []mimePart
+	if m.TextBody != "" || m.HTMLBody == "" {
+		part, err := textPart("text/plain", m.TextBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, part)
+	}
+	if m.HTMLBody != "" {
+		part, err := textPart("text/html", m.HTMLBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 1 {
+		return parts[0].header, parts[0].body, nil
+	}
+	return buildMultipart("alternative", parts)
+}
+
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func buildMultipart(subtype string, parts []mimePart) (textproto.MIMEHeader, []byte, error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(part.header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = partWriter.Write(part.body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", fmt.Sprintf("multipart/%s; boundary=%q", subtype, writer.Boundary()))
+	return header, buf.Bytes(), nil
+}
+
+func textPart(contentType, content string) (mimePart, error) {
+	buf := &bytes.Buffer{}
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return mimePart{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return mimePart{}, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=\"utf-8\"")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return mimePart{header: header, body: buf.Bytes()}, nil
+}
+
+func attachmentPart(attachment Attachment) mimePart {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if attachment.Inline {
+		disposition = "inline"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": attachment.Filename}))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	if attachment.ContentID != "" {
+		header.Set("Content-ID", "<"+attachment.ContentID+">")
+	}
+
+	return mimePart{header: header, body: encodeBase64Lines(attachment.Content)}
+}
+
+func encodeBase64Lines(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	buf := &bytes.Buffer{}
+	for len(encoded) > base64LineLength {
+		buf.WriteString(encoded[:base64LineLength] + "\r\n")
+		encoded = encoded[base64LineLength:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+func writeHeader(w io.Writer, key, value string) {
+	_, _ = fmt.Fprintf(w, "%s: %s\r\n", key, value)
+}
+
+func writeAddressHeader(w io.Writer, key string, addresses []mail.Address) {
+	if len(addresses) == 0 {
+		return
+	}
+	values := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		values = append(values, address.String())
+	}
+	writeHeader(w, key, strings.Join(values, ", "))
+}
+
+func generateMessageID(fromAddress string) (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(fromAddress, "@"); at >= 0 && at < len(fromAddress)-1 {
+		domain = fromAddress[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(randomBytes), domain), nil
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package smtp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+type parsedPart struct {
+	header map[string][]string
+	body   []byte
+}
+
+// readParts reads the raw parts of the multipart body, failing if the content type isn't multipart of the subtype.
+func readParts(t *testing.T, contentType string, body io.Reader, subtype string) []parsedPart {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("fail parse content type %q: %v", contentType, err)
+	}
+	if mediaType != "multipart/"+subtype {
+		t.Fatalf("expected multipart/%s, got %s", subtype, mediaType)
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	var parts []parsedPart
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("fail read multipart/%s part: %v", subtype, err)
+		}
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			t.Fatalf("fail read multipart/%s part: %v", subtype, err)
+		}
+		parts = append(parts, parsedPart{header: part.Header, body: content})
+	}
+}
+
+func (p parsedPart) get(key string) string {
+	if values := p.header[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func decodeQuotedPrintable(t *testing.T, part parsedPart) string {
+	t.Helper()
+	if encoding := part.get("Content-Transfer-Encoding"); encoding != "quoted-printable" {
+		t.Fatalf("expected quoted-printable, got %q", encoding)
+	}
+	content, err := ioutil.ReadAll(quotedprintable.NewReader(bytes.NewReader(part.body)))
+	if err != nil {
+		t.Fatalf("fail decode quoted-printable: %v", err)
+	}
+	return string(content)
+}
+
+func decodeBase64(t *testing.T, part parsedPart) []byte {
+	t.Helper()
+	if encoding := part.get("Content-Transfer-Encoding"); encoding != "base64" {
+		t.Fatalf("expected base64, got %q", encoding)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(part.body), "\r\n"), "\r\n") {
+		if len(line) > base64LineLength {
+			t.Errorf("base64 line is longer than %d: %d", base64LineLength, len(line))
+		}
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(part.body), "\r\n", ""))
+	if err != nil {
+		t.Fatalf("fail decode base64: %v", err)
+	}
+	return content
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	textBody := "Hello Pemain, your code is 123456. " + strings.Repeat("long line ", 20) + "ünïcødé"
+	htmlBody := `<p>Hello <b>Pemain</b>, <img src="cid:logo"></p>`
+	attachmentContent := bytes.Repeat([]byte{0, 1, 2, 250, 251, 252}, 50)
+	msg := &Message{
+		From:     mail.Address{Name: "My Game", Address: "noreply@mygame.com"},
+		To:       []mail.Address{{Name: "Player One", Address: "player1@example.com"}},
+		Cc:       []mail.Address{{Address: "cc@example.com"}},
+		Bcc:      []mail.Address{{Address: "secret-bcc@example.com"}},
+		ReplyTo:  []mail.Address{{Address: "support@mygame.com"}},
+		Subject:  "Vérification de votre compte",
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+		Headers:  map[string]string{"x-custom-header": "custom"},
+		Attachments: []Attachment{
+			{Filename: "logo.png", ContentType: "image/png", Content: []byte("png"), Inline: true, ContentID: "logo"},
+			{Filename: "receipt.bin", Content: attachmentContent},
+		},
+		Date:      time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+		MessageID: "<id@mygame.com>",
+	}
+
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("fail build message: %v", err)
+	}
+	if bytes.Contains(raw, []byte("secret-bcc")) {
+		t.Errorf("bcc recipient is written to the message")
+	}
+	recipients := msg.Recipients()
+	if len(recipients) != 3 || recipients[2] != "secret-bcc@example.com" {
+		t.Errorf("expected bcc in the envelope recipients, got %v", recipients)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("fail parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("unexpected subject %q, error: %v", subject, err)
+	}
+	expectedHeaders := map[string]string{
+		"From":            `"My Game" <noreply@mygame.com>`,
+		"To":              `"Player One" <player1@example.com>`,
+		"Cc":              "<cc@example.com>",
+		"Reply-To":        "<support@mygame.com>",
+		"Message-Id":      "<id@mygame.com>",
+		"Mime-Version":    "1.0",
+		"X-Custom-Header": "custom",
+		"Date":            "Mon, 01 May 2023 10:00:00 +0000",
+	}
+	for key, expected := range expectedHeaders {
+		if value := parsed.Header.Get(key); value != expected {
+			t.Errorf("unexpected %s header %q, want %q", key, value, expected)
+		}
+	}
+	if value := parsed.Header.Get("Bcc"); value != "" {
+		t.Errorf("unexpected Bcc header %q", value)
+	}
+
+	// multipart/mixed > multipart/related > multipart/alternative
+	mixed := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body, "mixed")
+	if len(mixed) != 2 {
+		t.Fatalf("expected 2 multipart/mixed parts, got %d", len(mixed))
+	}
+	attachment := mixed[1]
+	if disposition, params, _ := mime.ParseMediaType(attachment.get("Content-Disposition")); disposition != "attachment" || params["filename"] != "receipt.bin" {
+		t.Errorf("unexpected attachment disposition %q", attachment.get("Content-Disposition"))
+	}
+	if contentType, _, _ := mime.ParseMediaType(attachment.get("Content-Type")); contentType != "application/octet-stream" {
+		t.Errorf("unexpected attachment content type %q", attachment.get("Content-Type"))
+	}
+	if content := decodeBase64(t, attachment); !bytes.Equal(content, attachmentContent) {
+		t.Errorf("attachment content doesn't match")
+	}
+
+	related := readParts(t, mixed[0].get("Content-Type"), bytes.NewReader(mixed[0].body), "related")
+	if len(related) != 2 {
+		t.Fatalf("expected 2 multipart/related parts, got %d", len(related))
+	}
+	inline := related[1]
+	if inline.get("Content-Id") != "<logo>" {
+		t.Errorf("unexpected inline Content-ID %q", inline.get("Content-Id"))
+	}
+	if disposition, _, _ := mime.ParseMediaType(inline.get("Content-Disposition")); disposition != "inline" {
+		t.Errorf("unexpected inline disposition %q", inline.get("Content-Disposition"))
+	}
+	if content := decodeBase64(t, inline); string(content) != "png" {
+		t.Errorf("unexpected inline content %q", content)
+	}
+
+	alternative := readParts(t, related[0].get("Content-Type"), bytes.NewReader(related[0].body), "alternative")
+	if len(alternative) != 2 {
+		t.Fatalf("expected 2 multipart/alternative parts, got %d", len(alternative))
+	}
+	if contentType, params, _ := mime.ParseMediaType(alternative[0].get("Content-Type")); contentType != "text/plain" || params["charset"] != "utf-8" {
+		t.Errorf("unexpected text content type %q", alternative[0].get("Content-Type"))
+	}
+	for _, line := range strings.Split(string(alternative[0].body), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("quoted-printable line is longer than 76: %d", len(line))
+		}
+	}
+	if text := decodeQuotedPrintable(t, alternative[0]); text != textBody {
+		t.Errorf("unexpected text body %q", text)
+	}
+	if contentType, _, _ := mime.ParseMediaType(alternative[1].get("Content-Type")); contentType != "text/html" {
+		t.Errorf("unexpected html content type %q", alternative[1].get("Content-Type"))
+	}
+	if html := decodeQuotedPrintable(t, alternative[1]); html != htmlBody {
+		t.Errorf("unexpected html body %q", html)
+	}
+}
+
+func TestMessage_Bytes_TextOnly(t *testing.T) {
+	msg := &Message{
+		From:     mail.Address{Address: "noreply@mygame.com"},
+		Bcc:      []mail.Address{{Address: "bcc-only@example.com"}},
+		Subject:  "Hello",
+		TextBody: "plain",
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("fail build message: %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("fail parse message: %v", err)
+	}
+	if contentType, _, _ := mime.ParseMediaType(parsed.Header.Get("Content-Type")); contentType != "text/plain" {
+		t.Errorf("expected single text/plain part, got %q", parsed.Header.Get("Content-Type"))
+	}
+	if parsed.Header.Get("To") != "" || bytes.Contains(raw, []byte("bcc-only")) {
+		t.Errorf("bcc recipient is written to the message")
+	}
+	if !strings.HasSuffix(parsed.Header.Get("Message-Id"), "@mygame.com>") {
+		t.Errorf("unexpected generated Message-ID %q", parsed.Header.Get("Message-Id"))
+	}
+}
+
+func TestMessage_Bytes_Invalid(t *testing.T) {
+	if _, err := (&Message{To: []mail.Address{{Address: "a@example.com"}}}).Bytes(); err == nil {
+		t.Errorf("expected error without sender")
+	}
+	if _, err := (&Message{From: mail.Address{Address: "noreply@mygame.com"}}).Bytes(); err == nil {
+		t.Errorf("expected error without recipient")
+	}
+}
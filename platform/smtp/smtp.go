@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PlatformID = "smtp"
+
+	// TLSModeStartTLS upgrades the connection with STARTTLS, the server must support it.
+	TLSModeStartTLS = "starttls"
+	// TLSModeImplicit connects over TLS directly, usually on port 465.
+	TLSModeImplicit = "tls"
+	// TLSModeNone sends in plaintext, e.g. to a local relay or MailHog.
+	TLSModeNone = "none"
+
+	ImplicitTLSPort = 465
+
+	ConfigKeyHost                  = "host"
+	ConfigKeyPort                  = "port"
+	ConfigKeyUsername              = "username"
+	ConfigKeyPassword              = "password"
+	ConfigKeyAuth                  = "auth"
+	ConfigKeyTLSMode               = "tls_mode"
+	ConfigKeyTLSInsecureSkipVerify = "tls_insecure_skip_verify"
+
+	defaultPort = 587
+)
+
+func init() {
+	platform.Register(PlatformID, NewSMTPClientFromConfig)
+}
+
+type MailSender struct {
+	Host               string
+	Port               int
+	Username           string
+	Password           string
+	AuthMechanism      string
+	TLSMode            string
+	InsecureSkipVerify bool
+}
+
+// NewSMTPClient creates generic SMTP sender platform.
+// If tlsMode is empty, implicit TLS is used for port 465 and STARTTLS otherwise.
+func NewSMTPClient(host string, port int, username, password, authMechanism, tlsMode string) platform.SenderPlatform {
+	if tlsMode == "" {
+		tlsMode = TLSModeStartTLS
+		if port == ImplicitTLSPort {
+			tlsMode = TLSModeImplicit
+		}
+	}
+	if authMechanism == "" {
+		authMechanism = AuthNone
+		if username != "" {
+			authMechanism = AuthPlain
+		}
+	}
+	return &MailSender{
+		Host:          host,
+		Port:          port,
+		Username:      username,
+		Password:      password,
+		AuthMechanism: authMechanism,
+		TLSMode:       strings.ToLower(tlsMode),
+	}
+}
+
+// NewSMTPClientFromConfig creates generic SMTP sender platform from the generic platform configuration.
+func NewSMTPClientFromConfig(config platform.Config) (platform.SenderPlatform, error) {
+	host, err := config.GetRequired(ConfigKeyHost)
+	if err != nil {
+		return nil, err
+	}
+	port, err := config.GetInt(ConfigKeyPort, defaultPort)
+	if err != nil {
+		return nil, err
+	}
+	insecureSkipVerify, err := config.GetBool(ConfigKeyTLSInsecureSkipVerify, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := NewSMTPClient(
+		host,
+		port,
+		config.Get(ConfigKeyUsername),
+		config.Get(ConfigKeyPassword),
+		config.Get(ConfigKeyAuth),
+		config.Get(ConfigKeyTLSMode),
+	).(*MailSender)
+	sender.InsecureSkipVerify = insecureSkipVerify
+	// the configuration mistakes are reported here, instead of failing every send as a transport error
+	if err = sender.validate(); err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+func (e MailSender) Send(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendWithResult(ctx, emailData)
+	return err
+}
+
+func (e MailSender) SendWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if emailData.XMCTemplate != "" && emailData.HTMLBody == "" && emailData.TextBody == "" {
+		// the template must be rendered locally, otherwise the email would be sent without content
+		return nil, &platform.Error{
+			Provider: PlatformID,
+			Message:  fmt.Sprintf("template %s is not rendered, SMTP requires HTMLBody or TextBody", emailData.XMCTemplate),
+			Kind:     platform.ErrBadRequest,
+			Err:      platform.ErrTemplateNotSupported,
+		}
+	}
+
+	msg, err := NewMessage(emailData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := platform.NewSendResult(PlatformID)
+	err = e.SendMessage(ctx, msg)
+	if err != nil {
+		logrus.Errorf("Error send email to %s using SMTP: %s", emailData.To, err)
+		return nil, err
+	}
+	result.MessageID = msg.MessageID
+	return result.Complete(emailData), nil
+}
+
+// CanSendTemplate returns false, SMTP servers don't store templates, so XMCTemplate must be rendered locally.
+func (e MailSender) CanSendTemplate() bool {
+	return false
+}
+
+// SendMessage delivers the message through the configured SMTP server.
+func (e MailSender) SendMessage(ctx context.Context, msg *Message) error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+	msgBytes, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	auth, err := newAuth(e.AuthMechanism, e.Username, e.Password, e.Host)
+	if err != nil {
+		return err
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, time.Second*constant.DefaultHTTPTimeoutInSeconds)
+	defer cancel()
+
+	client, err := e.dial(subCtx)
+	if err != nil {
+		return platform.NewSMTPError(PlatformID, err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if e.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err = client.StartTLS(e.tlsConfig()); err != nil {
+			return platform.NewSMTPError(PlatformID, err)
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		if err = client.Auth(auth); err != nil {
+			return platform.NewSMTPError(PlatformID, err)
+		}
+	}
+
+	if err = client.Mail(msg.From.Address); err != nil {
+		return platform.NewSMTPError(PlatformID, err)
+	}
+	for _, recipient := range msg.Recipients() {
+		if err = client.Rcpt(recipient); err != nil {
+			platformErr := platform.NewSMTPError(PlatformID, err)
+			platformErr.Recipients = []string{recipient}
+			if !platformErr.Retryable && platformErr.StatusCode >= 500 {
+				platformErr.Kind = platform.ErrInvalidRecipient
+			}
+			return platformErr
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return platform.NewSMTPError(PlatformID, err)
+	}
+	if _, err = writer.Write(msgBytes); err != nil {
+		return platform.NewSMTPError(PlatformID, err)
+	}
+	if err = writer.Close(); err != nil {
+		return platform.NewSMTPError(PlatformID, err)
+	}
+	// the message is already accepted at this point, failing to quit gracefully is not an error
+	_ = client.Quit()
+	return nil
+}
+
+func (e MailSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+
+	var conn net.Conn
+	var err error
+	switch e.TLSMode {
+	case TLSModeImplicit:
+		dialer := &tls.Dialer{Config: e.tlsConfig()}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	default:
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// validate checks the TLS mode and the auth mechanism.
+func (e MailSender) validate() error {
+	switch e.TLSMode {
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return fmt.Errorf("unsupported smtp tls mode %s", e.TLSMode)
+	}
+	_, err := newAuth(e.AuthMechanism, e.Username, e.Password, e.Host)
+	return err
+}
+
+func (e MailSender) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName:         e.Host,
+		InsecureSkipVerify: e.InsecureSkipVerify, // nolint: gosec
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package smtp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+)
+
+func TestMailSender_SendWithResult_UnrenderedTemplate(t *testing.T) {
+	// nothing listens on the port, the email must be rejected before connecting
+	sender := NewSMTPClient("127.0.0.1", 1, "", "", "", TLSModeNone)
+	_, err := sender.(*MailSender).SendWithResult(context.Background(), object.EmailData{
+		From:        "noreply@mygame.com",
+		To:          "player@example.com",
+		Subject:     "Verify",
+		XMCTemplate: "verify",
+	})
+
+	var platformErr *platform.Error
+	if !errors.As(err, &platformErr) {
+		t.Fatalf("expected *platform.Error, got %v", err)
+	}
+	if !errors.Is(err, platform.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest, got %v", platformErr.Kind)
+	}
+	if !errors.Is(err, platform.ErrTemplateNotSupported) {
+		t.Errorf("expected ErrTemplateNotSupported, got %v", platformErr.Err)
+	}
+	if platformErr.Retryable {
+		t.Error("expected not retryable")
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package platform
+
+import "errors"
+
+var ErrTemplateNotSupported = errors.New("sender platform doesn't support provider templates")
+
+// TemplateSenderPlatform is implemented by the sender platforms able to tell whether they send EmailData.XMCTemplate
+// using the template stored by the provider. The platforms not implementing it are assumed to support the templates.
+type TemplateSenderPlatform interface {
+	SenderPlatform
+	CanSendTemplate() bool
+}
+
+// CanSendTemplate returns true if the sender platform sends EmailData.XMCTemplate using the provider template,
+// otherwise the email must be rendered locally before sending it.
+func CanSendTemplate(senderPlatform SenderPlatform) bool {
+	if templateSenderPlatform, ok := senderPlatform.(TemplateSenderPlatform); ok {
+		return templateSenderPlatform.CanSendTemplate()
+	}
+	return true
+}
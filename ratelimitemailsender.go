@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AccelByte/justice-go-common-email/configservice"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/platform/ratelimit"
+)
+
+// RateLimitEmailSender limits the emails sent through the wrapped email sender per namespace.
+type RateLimitEmailSender struct {
+	EmailSender EmailSender
+	Limiters    *ratelimit.Group
+	Mode        ratelimit.Mode
+}
+
+func NewRateLimitEmailSender(emailSender EmailSender, limit ratelimit.Limit, mode ratelimit.Mode) *RateLimitEmailSender {
+	return &RateLimitEmailSender{
+		EmailSender: emailSender,
+		Limiters:    ratelimit.NewGroup(limit),
+		Mode:        mode,
+	}
+}
+
+func (e *RateLimitEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+func (e *RateLimitEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if err := takeNamespaceRateLimit(ctx, e.Limiters.Get(emailData.Namespace), e.Mode, emailData.Namespace); err != nil {
+		return nil, err
+	}
+	return SendEmailWithResult(ctx, e.EmailSender, emailData)
+}
+
+// SendBatch takes the namespace rate limit once for the whole batch.
+func (e *RateLimitEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	if err := takeNamespaceRateLimit(ctx, e.Limiters.Get(emailData.Namespace), e.Mode, emailData.Namespace); err != nil {
+		return nil, err
+	}
+	return sendBatch(ctx, e.EmailSender, emailData, recipients)
+}
+
+func (e *RateLimitEmailSender) CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error {
+	return cancelScheduledEmail(ctx, e.EmailSender, namespace, scheduleID)
+}
+
+func takeNamespaceRateLimit(ctx context.Context, limiter *ratelimit.Limiter, mode ratelimit.Mode, namespace string) error {
+	err := limiter.Take(ctx, mode)
+	if errors.Is(err, ratelimit.ErrRateLimitExceeded) {
+		return fmt.Errorf("namespace %s: %w", namespace, err)
+	}
+	return err
+}
+
+// getRateLimitFromEnv returns the limit of the rate in emails per second and the burst, e.g. APP_EMAIL_NAMESPACE_RATE_LIMIT
+// and APP_EMAIL_NAMESPACE_RATE_LIMIT_BURST. It's unlimited if the rate is not set.
+func getRateLimitFromEnv(key string) (ratelimit.Limit, error) {
+	rate, err := getFloatEnv(key, 0)
+	if err != nil {
+		return ratelimit.Limit{}, err
+	}
+	burst, err := getIntEnv(key+"_BURST", 0)
+	if err != nil {
+		return ratelimit.Limit{}, err
+	}
+	return ratelimit.Limit{Rate: rate, Burst: burst}, nil
+}
+
+func toRateLimit(limit *configservice.RateLimit) ratelimit.Limit {
+	return ratelimit.Limit{Rate: limit.RatePerSecond, Burst: limit.Burst}
+}
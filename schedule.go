@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/schedule"
+	"github.com/sirupsen/logrus"
+)
+
+// ScheduledEmailSender is implemented by the email senders able to cancel the emails scheduled with EmailData.SendAt.
+type ScheduledEmailSender interface {
+	EmailSender
+	// CancelScheduledEmail cancels the email by SendResult.ScheduleID.
+	CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error
+}
+
+// sendOrSchedule sends the email through the sender platform, scheduling it locally
+// if it's scheduled later and the sender platform can't schedule it natively.
+func sendOrSchedule(ctx context.Context, scheduler *schedule.Scheduler, senderPlatform platform.SenderPlatform,
+	platformID string, emailData object.EmailData) (*platform.SendResult, error) {
+	if !emailData.IsScheduled() || platform.CanSchedule(senderPlatform, emailData.SendAt) {
+		return platform.SendWithResult(ctx, senderPlatform, platformID, emailData)
+	}
+	if scheduler == nil {
+		return nil, platform.ErrSchedulingNotSupported
+	}
+
+	// the attachment readers could be closed by the caller before the schedule
+	if err := emailData.ReadAttachments(); err != nil {
+		return nil, err
+	}
+	sendAt := emailData.SendAt
+	emailData.SendAt = time.Time{}
+	result := platform.NewSendResult(platformID)
+	result.ScheduleID = scheduler.Schedule(sendAt, func() {
+		if _, err := platform.SendWithResult(context.Background(), senderPlatform, platformID, emailData); err != nil {
+			logrus.Errorf("fail send scheduled email to %s. error: %v", emailData.To, err)
+		}
+	})
+	return result, nil
+}
+
+// cancelScheduled cancels the email scheduled locally, or by the sender platform.
+func cancelScheduled(ctx context.Context, scheduler *schedule.Scheduler, senderPlatform platform.SenderPlatform, scheduleID string) error {
+	if scheduler != nil && scheduler.Cancel(scheduleID) {
+		return nil
+	}
+	return platform.CancelScheduled(ctx, senderPlatform, scheduleID)
+}
+
+// cancelScheduledEmail cancels the scheduled email through the wrapped email sender, used by the email sender decorators.
+func cancelScheduledEmail(ctx context.Context, emailSender EmailSender, namespace, scheduleID string) error {
+	if scheduledEmailSender, ok := emailSender.(ScheduledEmailSender); ok {
+		return scheduledEmailSender.CancelScheduledEmail(ctx, namespace, scheduleID)
+	}
+	return platform.ErrSchedulingNotSupported
+}
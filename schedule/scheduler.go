@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Scheduler runs the scheduled functions in memory, so they are lost if the process stops before the schedule.
+type Scheduler struct {
+	lock   sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// Schedule runs fn at the given time and returns the schedule id to cancel it.
+func (s *Scheduler) Schedule(at time.Time, fn func()) string {
+	id := newScheduleID()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.timers[id] = time.AfterFunc(time.Until(at), func() {
+		s.lock.Lock()
+		_, found := s.timers[id]
+		delete(s.timers, id)
+		s.lock.Unlock()
+		if found {
+			fn()
+		}
+	})
+	return id
+}
+
+// Cancel cancels the scheduled function, returning false if it's not found, e.g. it's already run.
+func (s *Scheduler) Cancel(id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	timer, found := s.timers[id]
+	if !found {
+		return false
+	}
+	timer.Stop()
+	delete(s.timers, id)
+	return true
+}
+
+// Pending returns the count of the scheduled functions not run yet.
+func (s *Scheduler) Pending() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.timers)
+}
+
+func newScheduleID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "local-" + hex.EncodeToString(b)
+}
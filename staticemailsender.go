@@ -21,18 +21,33 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"strings"
 
 	"github.com/AccelByte/justice-go-common-email/object"
 	"github.com/AccelByte/justice-go-common-email/platform"
-	"github.com/AccelByte/justice-go-common-email/platform/mandrill"
-	"github.com/AccelByte/justice-go-common-email/platform/sendgrid"
+	"github.com/AccelByte/justice-go-common-email/platform/circuitbreaker"
+	"github.com/AccelByte/justice-go-common-email/platform/failover"
+	"github.com/AccelByte/justice-go-common-email/platform/ratelimit"
+	"github.com/AccelByte/justice-go-common-email/platform/retry"
+	"github.com/AccelByte/justice-go-common-email/schedule"
+	"github.com/AccelByte/justice-go-common-email/template"
+	"github.com/sirupsen/logrus"
 )
 
 type StaticEmailSender struct {
-	SenderPlatform platform.SenderPlatform
-	FromAddress    string
-	FromName       string
+	SenderPlatform   platform.SenderPlatform
+	SenderPlatformID string
+	FromAddress      string
+	FromName         string
+	// TemplateRenderer renders XMCTemplate locally when set, instead of relying on the template stored by the provider.
+	TemplateRenderer template.Renderer
+	// CircuitBreakers holds the circuit breaker of each platform by platform id, nil if the circuit breaker is disabled.
+	CircuitBreakers *circuitbreaker.Group
+	// NamespaceRateLimiters limits the emails sent per namespace, nil if the namespace rate limit is disabled.
+	NamespaceRateLimiters *ratelimit.Group
+	RateLimitMode         ratelimit.Mode
+	// Scheduler schedules the emails locally if the sender platform can't schedule them natively.
+	Scheduler *schedule.Scheduler
 }
 
 func NewStaticEmailSender() (*StaticEmailSender, error) {
@@ -46,60 +61,237 @@ func NewStaticEmailSender() (*StaticEmailSender, error) {
 	fromName = os.Getenv("FROM_EMAIL_NAME")
 
 	emailSender := &StaticEmailSender{
-		FromAddress: fromAddress,
-		FromName:    fromName,
+		SenderPlatformID: senderPlatform,
+		FromAddress:      fromAddress,
+		FromName:         fromName,
+		Scheduler:        schedule.NewScheduler(),
 	}
 
-	switch senderPlatform {
-	case sendgrid.PlatformID:
-		var apiKey, emailCategories string
-		if apiKey = os.Getenv("SENDGRID_API_KEY"); apiKey == "" {
-			return nil, errors.New("SENDGRID_API_KEY environment variable is not set")
-		}
-		emailCategories = os.Getenv("SENDGRID_EMAIL_CATEGORIES")
+	templateRenderer, err := getTemplateRendererFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	emailSender.TemplateRenderer = templateRenderer
+
+	circuitBreakerSettings, err := getCircuitBreakerSettingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if circuitBreakerSettings != nil {
+		emailSender.CircuitBreakers = circuitbreaker.NewGroup(*circuitBreakerSettings)
+	}
 
-		emailSender.SenderPlatform = sendgrid.NewSendGridClient(apiKey, emailCategories)
-	case mandrill.PlatformID:
-		apiURL := "https://mandrillapp.com"
-		smtpHost := "smtp.mandrillapp.com"
-		smtpPort := 587
-		var apiKey, smtpUsername, smtpPassword string
+	if emailSender.RateLimitMode, err = ratelimit.ParseMode(os.Getenv("APP_EMAIL_RATE_LIMIT_MODE")); err != nil {
+		return nil, err
+	}
+	namespaceRateLimit, err := getRateLimitFromEnv("APP_EMAIL_NAMESPACE_RATE_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	if !namespaceRateLimit.IsUnlimited() {
+		emailSender.NamespaceRateLimiters = ratelimit.NewGroup(namespaceRateLimit)
+	}
+	platformRateLimit, err := getRateLimitFromEnv("APP_EMAIL_PLATFORM_RATE_LIMIT")
+	if err != nil {
+		return nil, err
+	}
 
-		if str := os.Getenv("MANDRILL_API_URL"); str != "" {
-			apiURL = str
+	// several platforms could be specified as failover chain, e.g. "sendgrid,mandrill"
+	var failoverPlatforms []failover.Platform
+	for _, platformID := range strings.Split(senderPlatform, ",") {
+		platformID = strings.TrimSpace(platformID)
+		platformSender, err := newSenderPlatformFromEnv(platformID)
+		if err != nil {
+			return nil, err
 		}
-		if str := os.Getenv("MANDRILL_SMTP_HOST"); str != "" {
-			smtpHost = str
+		if emailSender.CircuitBreakers != nil {
+			platformSender = circuitbreaker.NewCircuitBreakerSenderPlatform(platformID, platformSender, emailSender.CircuitBreakers.Get(platformID))
 		}
-		if s := os.Getenv("MANDRILL_SMTP_PORT"); s != "" {
-			var err error
-			smtpPort, err = strconv.Atoi(s)
-			if err != nil {
-				return nil, errors.New("MANDRILL_SMTP_PORT value must be an integer")
-			}
+		if !platformRateLimit.IsUnlimited() {
+			limiter := ratelimit.NewLimiter(platformRateLimit)
+			platformSender = ratelimit.NewRateLimitSenderPlatform(platformID, platformSender, limiter, emailSender.RateLimitMode)
 		}
+		failoverPlatforms = append(failoverPlatforms, failover.Platform{ID: platformID, SenderPlatform: platformSender})
+	}
+	if len(failoverPlatforms) == 1 {
+		emailSender.SenderPlatform = failoverPlatforms[0].SenderPlatform
+	} else {
+		emailSender.SenderPlatform = failover.NewFailoverSenderPlatform(failoverPlatforms...)
+	}
 
-		apiKey = os.Getenv("MANDRILL_API_KEY")
-		smtpUsername = os.Getenv("MANDRILL_USERNAME")
-		smtpPassword = os.Getenv("MANDRILL_PASSWORD")
-
-		if apiURL != "" && apiKey != "" { //nolint: gocritic
-			emailSender.SenderPlatform = mandrill.NewMandrillClientWithAPIKey(apiURL, apiKey)
-		} else if smtpHost != "" || smtpPort != 0 || smtpUsername != "" || smtpPassword != "" {
-			emailSender.SenderPlatform = mandrill.NewMandrillClientWithSMTP(smtpHost, smtpPort, smtpUsername, smtpPassword)
-		} else {
-			return nil, errors.New("required mandrill environment variables is not set. For API Key: MANDRILL_API_URL, MANDRILL_API_KEY. For SMTP: MANDRILL_SMTP_HOST, MANDRILL_SMTP_PORT, MANDRILL_USERNAME, MANDRILL_PASSWORD")
-		}
-	default:
-		return nil, fmt.Errorf("%s email sender platform is not valid", senderPlatform)
+	emailSender.SenderPlatform, err = wrapRetrySenderPlatform(emailSender.SenderPlatform)
+	if err != nil {
+		return nil, err
 	}
 
 	return emailSender, nil
 }
 
+// GetCircuitBreakerStates returns the circuit state of each platform by platform id, e.g. for health checks.
+// It returns nil if the circuit breaker is disabled.
+func (e *StaticEmailSender) GetCircuitBreakerStates() map[string]circuitbreaker.State {
+	if e.CircuitBreakers == nil {
+		return nil
+	}
+	return e.CircuitBreakers.States()
+}
+
 func (e *StaticEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+func (e *StaticEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	if e.NamespaceRateLimiters != nil {
+		limiter := e.NamespaceRateLimiters.Get(emailData.Namespace)
+		if err := takeNamespaceRateLimit(ctx, limiter, e.RateLimitMode, emailData.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	emailData.SetTemplateAdditionalData()
+	emailData.From = e.FromAddress
+	emailData.FromName = e.FromName
+	if e.TemplateRenderer != nil && emailData.XMCTemplate != "" {
+		if err := renderTemplate(ctx, e.TemplateRenderer, &emailData); err != nil {
+			return nil, err
+		}
+	}
+	return sendOrSchedule(ctx, e.Scheduler, e.SenderPlatform, e.SenderPlatformID, emailData)
+}
+
+// SendBatch sends the email to the recipients, each with its own merge vars, in as few requests as the sender platform supports.
+// The namespace rate limit is taken once for the whole batch.
+// If TemplateRenderer is set, the email is rendered and sent to each recipient separately.
+func (e *StaticEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	if e.NamespaceRateLimiters != nil {
+		limiter := e.NamespaceRateLimiters.Get(emailData.Namespace)
+		if err := takeNamespaceRateLimit(ctx, limiter, e.RateLimitMode, emailData.Namespace); err != nil {
+			return nil, err
+		}
+	}
 	emailData.SetTemplateAdditionalData()
 	emailData.From = e.FromAddress
 	emailData.FromName = e.FromName
-	return e.SenderPlatform.Send(ctx, emailData)
+	if e.TemplateRenderer == nil || emailData.XMCTemplate == "" {
+		return sendBatchOrSchedule(ctx, e.Scheduler, e.SenderPlatform, e.SenderPlatformID, emailData, recipients)
+	}
+	return sendRenderedBatch(ctx, e.TemplateRenderer, e.Scheduler, e.SenderPlatform, e.SenderPlatformID, emailData, recipients), nil
+}
+
+// CancelScheduledEmail cancels the email scheduled with SendAt by SendResult.ScheduleID, the namespace is not used.
+func (e *StaticEmailSender) CancelScheduledEmail(ctx context.Context, _, scheduleID string) error {
+	return cancelScheduled(ctx, e.Scheduler, e.SenderPlatform, scheduleID)
+}
+
+func newSenderPlatformFromEnv(platformID string) (platform.SenderPlatform, error) {
+	senderPlatform, err := platform.New(platformID, getPlatformConfigFromEnv(platformID))
+	if err != nil {
+		if errors.Is(err, platform.ErrPlatformNotRegistered) {
+			return nil, fmt.Errorf("%s email sender platform is not valid", platformID)
+		}
+		return nil, fmt.Errorf("fail initialize %s email sender platform from %s_* environment variables: %s",
+			platformID, getPlatformEnvPrefix(platformID), err.Error())
+	}
+	return senderPlatform, nil
+}
+
+// wrapRetrySenderPlatform wraps the sender platform with retry if APP_EMAIL_RETRY_MAX_ATTEMPTS is more than 1.
+func wrapRetrySenderPlatform(senderPlatform platform.SenderPlatform) (platform.SenderPlatform, error) {
+	maxAttempts, err := getIntEnv("APP_EMAIL_RETRY_MAX_ATTEMPTS", 1)
+	if err != nil {
+		return nil, err
+	}
+	if maxAttempts <= 1 {
+		return senderPlatform, nil
+	}
+	initialBackoff, err := getMillisecondsEnv("APP_EMAIL_RETRY_INITIAL_BACKOFF", retry.DefaultInitialBackoff)
+	if err != nil {
+		return nil, err
+	}
+	maxBackoff, err := getMillisecondsEnv("APP_EMAIL_RETRY_MAX_BACKOFF", retry.DefaultMaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return retry.NewRetrySenderPlatform(senderPlatform, maxAttempts, initialBackoff, maxBackoff), nil
+}
+
+// getCircuitBreakerSettingsFromEnv returns nil if APP_EMAIL_CIRCUIT_BREAKER_FAILURE_RATE is not set, i.e. the circuit breaker is disabled.
+func getCircuitBreakerSettingsFromEnv() (*circuitbreaker.Settings, error) {
+	failureRate, err := getIntEnv("APP_EMAIL_CIRCUIT_BREAKER_FAILURE_RATE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if failureRate <= 0 {
+		return nil, nil
+	}
+	if failureRate > 100 {
+		return nil, errors.New("APP_EMAIL_CIRCUIT_BREAKER_FAILURE_RATE value must be between 1 and 100")
+	}
+	minRequests, err := getIntEnv("APP_EMAIL_CIRCUIT_BREAKER_MIN_REQUESTS", circuitbreaker.DefaultMinRequests)
+	if err != nil {
+		return nil, err
+	}
+	window, err := getMillisecondsEnv("APP_EMAIL_CIRCUIT_BREAKER_WINDOW", circuitbreaker.DefaultWindow)
+	if err != nil {
+		return nil, err
+	}
+	coolDown, err := getMillisecondsEnv("APP_EMAIL_CIRCUIT_BREAKER_COOL_DOWN", circuitbreaker.DefaultCoolDown)
+	if err != nil {
+		return nil, err
+	}
+	return &circuitbreaker.Settings{
+		FailureRateThreshold: float64(failureRate) / 100,
+		MinRequests:          minRequests,
+		Window:               window,
+		CoolDown:             coolDown,
+	}, nil
+}
+
+// getPlatformConfigFromEnv collects the environment variables prefixed by the platform id as the platform configuration,
+// e.g. SENDGRID_API_KEY becomes "api_key" for the sendgrid platform.
+func getPlatformConfigFromEnv(platformID string) platform.Config {
+	prefix := getPlatformEnvPrefix(platformID) + "_"
+	config := platform.Config{}
+	for _, env := range os.Environ() {
+		pair := strings.SplitN(env, "=", 2)
+		if len(pair) != 2 || !strings.HasPrefix(pair[0], prefix) {
+			continue
+		}
+		config[strings.ToLower(strings.TrimPrefix(pair[0], prefix))] = pair[1]
+	}
+	return config
+}
+
+func getPlatformEnvPrefix(platformID string) string {
+	return strings.ToUpper(strings.ReplaceAll(platformID, "-", "_"))
+}
+
+// getTemplateRendererFromEnv returns the renderer of the templates in APP_EMAIL_TEMPLATE_DIR, or nil if it's not set.
+func getTemplateRendererFromEnv() (template.Renderer, error) {
+	templateDir := os.Getenv("APP_EMAIL_TEMPLATE_DIR")
+	if templateDir == "" {
+		return nil, nil
+	}
+	renderer, err := template.NewDirRenderer(templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("fail load email templates: %s", err.Error())
+	}
+	return renderer, nil
+}
+
+// renderTemplate renders XMCTemplate into the email content.
+// XMCTemplate is cleared afterwards, so the platforms send the rendered content instead of the provider template.
+func renderTemplate(ctx context.Context, renderer template.Renderer, emailData *object.EmailData) error {
+	content, err := renderer.Render(ctx, emailData.XMCTemplate, emailData.XMCMergeVars)
+	if err != nil {
+		logrus.Errorf("fail render email template %s. error: %v", emailData.XMCTemplate, err)
+		return err
+	}
+	if content.Subject != "" {
+		emailData.Subject = content.Subject
+	}
+	emailData.HTMLBody = content.HTML
+	emailData.TextBody = content.Text
+	emailData.XMCTemplate = ""
+	return nil
 }
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+	"testing/fstest"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/template"
+)
+
+// recordingSenderPlatform records the emails sent through it.
+type recordingSenderPlatform struct {
+	sent []object.EmailData
+}
+
+func (p *recordingSenderPlatform) Send(ctx context.Context, emailData object.EmailData) error {
+	p.sent = append(p.sent, emailData)
+	return nil
+}
+
+func newTestStaticEmailSender(t *testing.T) (*StaticEmailSender, *recordingSenderPlatform) {
+	renderer, err := template.NewFSRenderer(fstest.MapFS{
+		"verify.subject.tmpl": {Data: []byte("Verify your account")},
+		"verify.html.tmpl":    {Data: []byte("<p>Your code is {{.code}}</p>")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderPlatform := &recordingSenderPlatform{}
+	return &StaticEmailSender{
+		SenderPlatform:   senderPlatform,
+		SenderPlatformID: "recording",
+		FromAddress:      "noreply@mygame.com",
+		TemplateRenderer: renderer,
+	}, senderPlatform
+}
+
+func TestStaticEmailSender_RenderTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		emailData   object.EmailData
+		expectedErr error
+		expectErr   bool
+		expectSent  bool
+	}{
+		{
+			name:       "rendered",
+			emailData:  object.EmailData{To: "player@example.com", XMCTemplate: "verify", XMCMergeVars: map[string]interface{}{"code": "123456"}},
+			expectSent: true,
+		},
+		{
+			name:      "missing merge var",
+			emailData: object.EmailData{To: "player@example.com", XMCTemplate: "verify"},
+			expectErr: true,
+		},
+		{
+			name:        "template not found",
+			emailData:   object.EmailData{To: "player@example.com", XMCTemplate: "reset"},
+			expectedErr: template.ErrTemplateNotFound,
+			expectErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			emailSender, senderPlatform := newTestStaticEmailSender(t)
+			_, err := emailSender.SendEmailWithResult(context.Background(), testCase.emailData)
+			if testCase.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", testCase.expectErr, err)
+			}
+			if testCase.expectedErr != nil && !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("expected %v, got %v", testCase.expectedErr, err)
+			}
+			if !testCase.expectSent {
+				if len(senderPlatform.sent) != 0 {
+					t.Errorf("expected the unrendered email not sent, got %+v", senderPlatform.sent)
+				}
+				return
+			}
+
+			if len(senderPlatform.sent) != 1 {
+				t.Fatalf("expected sent once, got %d", len(senderPlatform.sent))
+			}
+			sent := senderPlatform.sent[0]
+			if sent.Subject != "Verify your account" || sent.HTMLBody != "<p>Your code is 123456</p>" || sent.XMCTemplate != "" {
+				t.Errorf("expected the rendered content without template, got %+v", sent)
+			}
+		})
+	}
+}
+
+func TestStaticEmailSender_SendBatch_RenderTemplate(t *testing.T) {
+	emailSender, senderPlatform := newTestStaticEmailSender(t)
+	results, err := emailSender.SendBatch(context.Background(), object.EmailData{XMCTemplate: "verify"}, []object.Recipient{
+		{Address: mail.Address{Address: "a@example.com"}, MergeVars: map[string]interface{}{"code": "111111"}},
+		{Address: mail.Address{Address: "b@example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("expected only the recipient without merge vars failed, got %+v", results)
+	}
+	if len(senderPlatform.sent) != 1 || senderPlatform.sent[0].To != "a@example.com" || senderPlatform.sent[0].HTMLBody != "<p>Your code is 111111</p>" {
+		t.Errorf("expected only the rendered email of a@example.com sent, got %+v", senderPlatform.sent)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import "github.com/AccelByte/justice-go-common-email/internal/sqldialect"
+
+// Dialect is the SQL dialect of the suppression table.
+type Dialect struct {
+	Name string
+
+	createTable string
+	base        sqldialect.Dialect
+}
+
+var (
+	DialectPostgres = Dialect{
+		Name: "postgres",
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+	namespace VARCHAR(255) NOT NULL,
+	address VARCHAR(320) NOT NULL,
+	reason VARCHAR(32) NOT NULL,
+	provider VARCHAR(64) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (namespace, address)
+)`,
+		base: sqldialect.Postgres,
+	}
+
+	// DialectSQLite requires SQLite 3.24 or later for the ON CONFLICT clause.
+	DialectSQLite = Dialect{
+		Name: "sqlite",
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+	namespace TEXT NOT NULL,
+	address TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	provider TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	PRIMARY KEY (namespace, address)
+)`,
+		base: sqldialect.SQLite,
+	}
+)
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+	"github.com/sirupsen/logrus"
+)
+
+// Handler suppresses the recipients of the hard bounced, complained and unsubscribed delivery events.
+// The webhook handlers don't feed it by themselves, pass it to them combined with the other handlers,
+// e.g. using NewEventHandler or events.Handlers.
+type Handler struct {
+	Store Store
+	/*
+		DefaultNamespace suppresses the recipients of the events without namespace for this namespace,
+		e.g. the service only sends the emails of a namespace. The events without namespace are reported
+		for the emails not sent by this library, and they're skipped if DefaultNamespace is empty.
+	*/
+	DefaultNamespace string
+}
+
+func NewHandler(store Store) *Handler {
+	return &Handler{
+		Store: store,
+	}
+}
+
+// NewEventHandler returns the delivery event handler suppressing the recipients, then passing the event to eventHandler
+// (optional), to be passed to the webhook handlers.
+func NewEventHandler(store Store, eventHandler events.Handler) events.Handler {
+	if eventHandler == nil {
+		return NewHandler(store)
+	}
+	return events.Handlers{NewHandler(store), eventHandler}
+}
+
+func (h *Handler) HandleDeliveryEvent(ctx context.Context, event events.DeliveryEvent) error {
+	if !event.Type.IsPermanentFailure() || event.Recipient == "" {
+		return nil
+	}
+	namespace := event.Namespace
+	if namespace == "" {
+		namespace = h.DefaultNamespace
+	}
+	if namespace == "" {
+		// the suppression without namespace wouldn't match any email
+		logrus.Warnf("email recipient %s is not suppressed, %s %s event has no namespace", event.Recipient, event.Provider, event.Type)
+		return nil
+	}
+	createdAt := event.Timestamp
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	err := h.Store.Add(ctx, Entry{
+		Namespace: namespace,
+		Address:   event.Recipient,
+		Reason:    event.Type,
+		Provider:  event.Provider,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		logrus.Errorf("fail suppress email recipient %s of namespace %s. error: %v", event.Recipient, namespace, err)
+		return err
+	}
+	logrus.Infof("email recipient %s of namespace %s is suppressed: %s", event.Recipient, namespace, event.Type)
+	return nil
+}
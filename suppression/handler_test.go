@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+func TestHandler_HandleDeliveryEvent(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		name             string
+		defaultNamespace string
+		event            events.DeliveryEvent
+		// expectedNamespace is empty if the recipient isn't suppressed
+		expectedNamespace string
+	}{
+		{
+			name:              "hard bounce",
+			event:             events.DeliveryEvent{Type: events.TypeBouncedHard, Namespace: "mygame", Recipient: "player@example.com"},
+			expectedNamespace: "mygame",
+		},
+		{
+			name:              "complaint",
+			event:             events.DeliveryEvent{Type: events.TypeComplained, Namespace: "mygame", Recipient: "player@example.com"},
+			expectedNamespace: "mygame",
+		},
+		{
+			name:              "unsubscribe",
+			event:             events.DeliveryEvent{Type: events.TypeUnsubscribed, Namespace: "mygame", Recipient: "player@example.com"},
+			expectedNamespace: "mygame",
+		},
+		{
+			name:  "soft bounce",
+			event: events.DeliveryEvent{Type: events.TypeBouncedSoft, Namespace: "mygame", Recipient: "player@example.com"},
+		},
+		{
+			name:  "delivered",
+			event: events.DeliveryEvent{Type: events.TypeDelivered, Namespace: "mygame", Recipient: "player@example.com"},
+		},
+		{
+			name:  "no recipient",
+			event: events.DeliveryEvent{Type: events.TypeBouncedHard, Namespace: "mygame"},
+		},
+		{
+			name:  "no namespace",
+			event: events.DeliveryEvent{Type: events.TypeBouncedHard, Recipient: "player@example.com"},
+		},
+		{
+			name:              "no namespace with default namespace",
+			defaultNamespace:  "mygame",
+			event:             events.DeliveryEvent{Type: events.TypeBouncedHard, Recipient: "player@example.com"},
+			expectedNamespace: "mygame",
+		},
+		{
+			name:              "namespace over default namespace",
+			defaultNamespace:  "othergame",
+			event:             events.DeliveryEvent{Type: events.TypeBouncedHard, Namespace: "mygame", Recipient: "player@example.com"},
+			expectedNamespace: "mygame",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			handler := NewHandler(store)
+			handler.DefaultNamespace = tc.defaultNamespace
+			event := tc.event
+			event.Provider = "sendgrid"
+			event.Timestamp = timestamp
+			if err := handler.HandleDeliveryEvent(context.Background(), event); err != nil {
+				t.Fatal(err)
+			}
+
+			var suppressed []Entry
+			for _, namespace := range []string{"", "mygame", "othergame"} {
+				entries, _ := store.GetSuppressed(context.Background(), namespace, []string{"player@example.com"})
+				suppressed = append(suppressed, entries...)
+			}
+			if tc.expectedNamespace == "" {
+				if len(suppressed) != 0 {
+					t.Errorf("expected not suppressed, got %v", suppressed)
+				}
+				return
+			}
+			if len(suppressed) != 1 {
+				t.Fatalf("expected suppressed once, got %v", suppressed)
+			}
+			expected := Entry{Namespace: tc.expectedNamespace, Address: "player@example.com", Reason: event.Type, Provider: "sendgrid", CreatedAt: timestamp}
+			if suppressed[0] != expected {
+				t.Errorf("expected %+v, got %+v", expected, suppressed[0])
+			}
+		})
+	}
+}
+
+func TestNewEventHandler(t *testing.T) {
+	store := NewMemoryStore()
+	var handled []events.DeliveryEvent
+	eventHandler := NewEventHandler(store, events.HandlerFunc(func(ctx context.Context, event events.DeliveryEvent) error {
+		handled = append(handled, event)
+		return nil
+	}))
+
+	event := events.DeliveryEvent{Type: events.TypeBouncedHard, Namespace: "mygame", Recipient: "player@example.com"}
+	if err := eventHandler.HandleDeliveryEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := store.GetSuppressed(context.Background(), "mygame", []string{"player@example.com"}); len(entries) != 1 {
+		t.Errorf("expected suppressed, got %v", entries)
+	}
+	if len(handled) != 1 {
+		t.Errorf("expected the event passed to the event handler, got %d", len(handled))
+	}
+
+	if err := NewEventHandler(store, nil).HandleDeliveryEvent(context.Background(), event); err != nil {
+		t.Errorf("expected handled without event handler, got %v", err)
+	}
+}
+
+type failingStore struct {
+	MemoryStore
+}
+
+func (s *failingStore) Add(ctx context.Context, entry Entry) error {
+	return errors.New("database is down")
+}
+
+func TestHandler_StoreError(t *testing.T) {
+	handler := NewHandler(&failingStore{})
+	event := events.DeliveryEvent{Type: events.TypeBouncedHard, Namespace: "mygame", Recipient: "player@example.com"}
+	// the error makes the webhook respond with failure, so the provider retries
+	if err := handler.HandleDeliveryEvent(context.Background(), event); err == nil {
+		t.Error("expected the store error")
+	}
+}
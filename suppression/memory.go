@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore records the suppressed addresses in memory, so they're lost on restart and not shared between instances.
+type MemoryStore struct {
+	mutex   sync.RWMutex
+	entries map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]Entry),
+	}
+}
+
+func (s *MemoryStore) Add(_ context.Context, entry Entry) error {
+	entry.Address = NormalizeAddress(entry.Address)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[memoryKey(entry.Namespace, entry.Address)] = entry
+	return nil
+}
+
+func (s *MemoryStore) GetSuppressed(_ context.Context, namespace string, addresses []string) ([]Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var entries []Entry
+	for _, address := range addresses {
+		if entry, found := s.entries[memoryKey(namespace, NormalizeAddress(address))]; found {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) Remove(_ context.Context, namespace, address string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, memoryKey(namespace, NormalizeAddress(address)))
+	return nil
+}
+
+func memoryKey(namespace, address string) string {
+	return namespace + ":" + address
+}
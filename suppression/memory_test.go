@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, entry := range []Entry{
+		{Namespace: "mygame", Address: " Player@Example.com ", Reason: events.TypeBouncedHard, Provider: "sendgrid", CreatedAt: createdAt},
+		{Namespace: "mygame", Address: "spam@example.com", Reason: events.TypeComplained, Provider: "mandrill", CreatedAt: createdAt},
+		{Namespace: "othergame", Address: "other@example.com", Reason: events.TypeUnsubscribed, CreatedAt: createdAt},
+	} {
+		if err := store.Add(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		namespace string
+		addresses []string
+		expected  []string
+	}{
+		{name: "case-insensitive", namespace: "mygame", addresses: []string{"PLAYER@example.com"}, expected: []string{"player@example.com"}},
+		{name: "only suppressed", namespace: "mygame", addresses: []string{"ok@example.com", "spam@example.com"}, expected: []string{"spam@example.com"}},
+		{name: "per namespace", namespace: "mygame", addresses: []string{"other@example.com"}},
+		{name: "other namespace", namespace: "othergame", addresses: []string{"other@example.com", "player@example.com"}, expected: []string{"other@example.com"}},
+		{name: "no addresses", namespace: "mygame"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := store.GetSuppressed(ctx, tc.namespace, tc.addresses)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tc.expected) {
+				t.Fatalf("expected %v suppressed, got %v", tc.expected, entries)
+			}
+			for i, entry := range entries {
+				if entry.Address != tc.expected[i] || entry.Namespace != tc.namespace || !entry.CreatedAt.Equal(createdAt) {
+					t.Errorf("expected %s of %s suppressed, got %+v", tc.expected[i], tc.namespace, entry)
+				}
+			}
+		})
+	}
+
+	t.Run("add replaces the entry", func(t *testing.T) {
+		if err := store.Add(ctx, Entry{Namespace: "mygame", Address: "player@example.com", Reason: events.TypeUnsubscribed}); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := store.GetSuppressed(ctx, "mygame", []string{"player@example.com"})
+		if len(entries) != 1 || entries[0].Reason != events.TypeUnsubscribed {
+			t.Errorf("expected the entry replaced, got %v", entries)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if err := store.Remove(ctx, "mygame", "Player@Example.com"); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := store.GetSuppressed(ctx, "mygame", []string{"player@example.com", "spam@example.com"})
+		if len(entries) != 1 || entries[0].Address != "spam@example.com" {
+			t.Errorf("expected only spam@example.com suppressed, got %v", entries)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+const DefaultTable = "email_suppression"
+
+// SQLStore records the suppressed addresses in the suppression table, shared by all the instances.
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect Dialect
+	Table   string
+}
+
+func NewSQLStore(db *sql.DB, dialect Dialect) *SQLStore {
+	return &SQLStore{
+		DB:      db,
+		Dialect: dialect,
+		Table:   DefaultTable,
+	}
+}
+
+// CreateTable creates the suppression table if it doesn't exist yet.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	if _, err := s.DB.ExecContext(ctx, fmt.Sprintf(s.Dialect.createTable, s.Table)); err != nil {
+		return fmt.Errorf("fail create email suppression table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLStore) Add(ctx context.Context, entry Entry) error {
+	query := s.Dialect.base.Rebind(fmt.Sprintf(`INSERT INTO %s (namespace, address, reason, provider, created_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (namespace, address) DO UPDATE SET reason = excluded.reason, provider = excluded.provider, created_at = excluded.created_at`, s.Table))
+	_, err := s.DB.ExecContext(ctx, query, entry.Namespace, NormalizeAddress(entry.Address), string(entry.Reason),
+		entry.Provider, s.Dialect.base.TimeValue(entry.CreatedAt))
+	return err
+}
+
+func (s *SQLStore) GetSuppressed(ctx context.Context, namespace string, addresses []string) ([]Entry, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, len(addresses)+1)
+	args = append(args, namespace)
+	for _, address := range addresses {
+		args = append(args, NormalizeAddress(address))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(addresses)), ", ")
+	query := s.Dialect.base.Rebind(fmt.Sprintf(`SELECT namespace, address, reason, provider, created_at FROM %s
+WHERE namespace = ? AND address IN (%s)`, s.Table, placeholders))
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var reason string
+		if err = rows.Scan(&entry.Namespace, &entry.Address, &reason, &entry.Provider, s.Dialect.base.ScanTime(&entry.CreatedAt)); err != nil {
+			return nil, err
+		}
+		entry.Reason = events.Type(reason)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *SQLStore) Remove(ctx context.Context, namespace, address string) error {
+	query := s.Dialect.base.Rebind(fmt.Sprintf("DELETE FROM %s WHERE namespace = ? AND address = ?", s.Table))
+	_, err := s.DB.ExecContext(ctx, query, namespace, NormalizeAddress(address))
+	return err
+}
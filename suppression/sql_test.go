@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+// fakeConn records the executed queries and returns the given rows, it's enough to test the queries of SQLStore.
+type fakeConn struct {
+	queries [][]interface{}
+	rows    [][]driver.Value
+}
+
+func (c *fakeConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                        { return nil }
+func (c *fakeConn) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                                 { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args)
+	return &fakeRows{rows: c.rows}, nil
+}
+
+func (c *fakeConn) record(query string, args []driver.NamedValue) {
+	recorded := []interface{}{query}
+	for _, arg := range args {
+		recorded = append(recorded, arg.Value)
+	}
+	c.queries = append(c.queries, recorded)
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"namespace", "address", "reason", "provider", "created_at"}
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newTestSQLStore(dialect Dialect, rows [][]driver.Value) (*SQLStore, *fakeConn) {
+	conn := &fakeConn{rows: rows}
+	return NewSQLStore(sql.OpenDB(conn), dialect), conn
+}
+
+func TestSQLStore_Add(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.FixedZone("UTC+7", 7*60*60))
+	testCases := []struct {
+		name          string
+		dialect       Dialect
+		placeholders  string
+		expectedValue interface{}
+	}{
+		{name: "postgres", dialect: DialectPostgres, placeholders: "($1, $2, $3, $4, $5)", expectedValue: createdAt.UTC()},
+		{name: "sqlite", dialect: DialectSQLite, placeholders: "(?, ?, ?, ?, ?)", expectedValue: "2024-01-01 20:04:05.006"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, conn := newTestSQLStore(tc.dialect, nil)
+			err := store.Add(context.Background(), Entry{
+				Namespace: "mygame",
+				Address:   " Player@Example.com",
+				Reason:    events.TypeBouncedHard,
+				Provider:  "sendgrid",
+				CreatedAt: createdAt,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(conn.queries) != 1 {
+				t.Fatalf("expected 1 query, got %d", len(conn.queries))
+			}
+			query := conn.queries[0][0].(string)
+			if !strings.Contains(query, "INSERT INTO email_suppression") || !strings.Contains(query, "VALUES "+tc.placeholders) ||
+				!strings.Contains(query, "ON CONFLICT (namespace, address) DO UPDATE") {
+				t.Errorf("unexpected query %s", query)
+			}
+			expectedArgs := []interface{}{"mygame", "player@example.com", "bounced-hard", "sendgrid", tc.expectedValue}
+			if args := conn.queries[0][1:]; !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("expected args %v, got %v", expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestSQLStore_GetSuppressed(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	testCases := []struct {
+		name         string
+		dialect      Dialect
+		createdAt    driver.Value
+		placeholders string
+	}{
+		{name: "postgres", dialect: DialectPostgres, createdAt: createdAt, placeholders: "namespace = $1 AND address IN ($2, $3)"},
+		{name: "sqlite", dialect: DialectSQLite, createdAt: "2024-01-02 03:04:05.006", placeholders: "namespace = ? AND address IN (?, ?)"},
+		{name: "sqlite bytes", dialect: DialectSQLite, createdAt: []byte("2024-01-02 03:04:05.006"), placeholders: "namespace = ? AND address IN (?, ?)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, conn := newTestSQLStore(tc.dialect, [][]driver.Value{
+				{"mygame", "player@example.com", "bounced-hard", "sendgrid", tc.createdAt},
+			})
+			entries, err := store.GetSuppressed(context.Background(), "mygame", []string{"Player@Example.com", "ok@example.com"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := []Entry{{Namespace: "mygame", Address: "player@example.com", Reason: events.TypeBouncedHard, Provider: "sendgrid", CreatedAt: createdAt}}
+			if !reflect.DeepEqual(entries, expected) {
+				t.Errorf("expected %+v, got %+v", expected, entries)
+			}
+			if query := conn.queries[0][0].(string); !strings.Contains(query, tc.placeholders) {
+				t.Errorf("expected %s in query %s", tc.placeholders, query)
+			}
+			expectedArgs := []interface{}{"mygame", "player@example.com", "ok@example.com"}
+			if args := conn.queries[0][1:]; !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("expected args %v, got %v", expectedArgs, args)
+			}
+		})
+	}
+
+	t.Run("no addresses", func(t *testing.T) {
+		store, conn := newTestSQLStore(DialectPostgres, nil)
+		entries, err := store.GetSuppressed(context.Background(), "mygame", nil)
+		if err != nil || entries != nil || len(conn.queries) != 0 {
+			t.Errorf("expected no query, got %v %v %v", entries, err, conn.queries)
+		}
+	})
+}
+
+func TestSQLStore_Remove(t *testing.T) {
+	store, conn := newTestSQLStore(DialectPostgres, nil)
+	if err := store.Remove(context.Background(), "mygame", "Player@Example.com"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{"DELETE FROM email_suppression WHERE namespace = $1 AND address = $2", "mygame", "player@example.com"}
+	if !reflect.DeepEqual(conn.queries[0], expected) {
+		t.Errorf("expected %v, got %v", expected, conn.queries[0])
+	}
+}
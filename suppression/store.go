@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package suppression
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+// Entry is the address suppressed for a namespace, i.e. no more emails of the namespace are sent to it.
+type Entry struct {
+	Namespace string
+	Address   string
+	// Reason is the delivery event that suppressed the address, e.g. bounced-hard.
+	Reason events.Type
+	// Provider is the sender platform id that reported the event, e.g. "sendgrid".
+	Provider  string
+	CreatedAt time.Time
+}
+
+// Store records the suppressed addresses per namespace. The addresses are compared case-insensitively.
+type Store interface {
+	// Add suppresses the address for the namespace, replacing the existing entry if any.
+	Add(ctx context.Context, entry Entry) error
+	// GetSuppressed returns the entries of the given addresses suppressed for the namespace.
+	GetSuppressed(ctx context.Context, namespace string, addresses []string) ([]Entry, error)
+	// Remove lifts the suppression of the address, e.g. the recipient subscribed again.
+	Remove(ctx context.Context, namespace, address string) error
+}
+
+// NormalizeAddress returns the address as it's stored, trimmed and lowercased.
+func NormalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
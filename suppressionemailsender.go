@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/suppression"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrRecipientSuppressed = errors.New("email recipient is suppressed")
+
+// SuppressionEmailSender doesn't send the email to the recipients suppressed for the namespace,
+// i.e. the addresses that hard bounced, complained or unsubscribed. The suppressed recipients are removed from the email
+// and reported as rejected, and if none of the To recipients is left, ErrRecipientSuppressed is returned.
+type SuppressionEmailSender struct {
+	EmailSender EmailSender
+	Store       suppression.Store
+}
+
+func NewSuppressionEmailSender(emailSender EmailSender, store suppression.Store) *SuppressionEmailSender {
+	return &SuppressionEmailSender{
+		EmailSender: emailSender,
+		Store:       store,
+	}
+}
+
+func (e *SuppressionEmailSender) SendEmail(ctx context.Context, emailData object.EmailData) error {
+	_, err := e.SendEmailWithResult(ctx, emailData)
+	return err
+}
+
+func (e *SuppressionEmailSender) SendEmailWithResult(ctx context.Context, emailData object.EmailData) (*platform.SendResult, error) {
+	recipients := emailData.GetRecipients()
+	suppressed, err := e.getSuppressed(ctx, emailData.Namespace, recipients)
+	if err != nil {
+		return nil, err
+	}
+	if len(suppressed) == 0 {
+		return SendEmailWithResult(ctx, e.EmailSender, emailData)
+	}
+
+	rejected := make([]string, 0, len(suppressed))
+	for _, recipient := range recipients {
+		if suppressed[suppression.NormalizeAddress(recipient)] {
+			rejected = append(rejected, recipient)
+		}
+	}
+	emailData = removeSuppressedRecipients(emailData, suppressed)
+	if len(emailData.GetToAddresses()) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRecipientSuppressed, strings.Join(rejected, ", "))
+	}
+
+	logrus.Infof("email of namespace %s is not sent to the suppressed recipients %s", emailData.Namespace, strings.Join(rejected, ", "))
+	result, err := SendEmailWithResult(ctx, e.EmailSender, emailData)
+	if result != nil {
+		result.RejectedRecipients = append(result.RejectedRecipients, rejected...)
+	}
+	return result, err
+}
+
+// SendBatch sends the email to the recipients not suppressed, the suppressed recipients are reported
+// in a separate batch result with ErrRecipientSuppressed.
+func (e *SuppressionEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	suppressed, err := e.getSuppressed(ctx, emailData.Namespace, object.GetRecipientAddresses(recipients))
+	if err != nil {
+		return nil, err
+	}
+	if len(suppressed) == 0 {
+		return sendBatch(ctx, e.EmailSender, emailData, recipients)
+	}
+
+	allowed := make([]object.Recipient, 0, len(recipients))
+	var rejected []string
+	for _, recipient := range recipients {
+		if suppressed[suppression.NormalizeAddress(recipient.Address.Address)] {
+			rejected = append(rejected, recipient.Address.Address)
+			continue
+		}
+		allowed = append(allowed, recipient)
+	}
+	suppressedResult := platform.BatchResult{Recipients: rejected, Err: ErrRecipientSuppressed}
+	if len(allowed) == 0 {
+		return []platform.BatchResult{suppressedResult}, nil
+	}
+	results, err := sendBatch(ctx, e.EmailSender, emailData, allowed)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, suppressedResult), nil
+}
+
+func (e *SuppressionEmailSender) CancelScheduledEmail(ctx context.Context, namespace, scheduleID string) error {
+	return cancelScheduledEmail(ctx, e.EmailSender, namespace, scheduleID)
+}
+
+// getSuppressed returns the normalized addresses suppressed for the namespace.
+func (e *SuppressionEmailSender) getSuppressed(ctx context.Context, namespace string, addresses []string) (map[string]bool, error) {
+	entries, err := e.Store.GetSuppressed(ctx, namespace, addresses)
+	if err != nil {
+		logrus.Errorf("fail get suppressed email recipients of namespace %s. error: %v", namespace, err)
+		return nil, err
+	}
+	suppressed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		suppressed[suppression.NormalizeAddress(entry.Address)] = true
+	}
+	return suppressed, nil
+}
+
+func removeSuppressedRecipients(emailData object.EmailData, suppressed map[string]bool) object.EmailData {
+	if suppressed[suppression.NormalizeAddress(emailData.To)] {
+		emailData.To = ""
+	}
+	emailData.ToList = removeSuppressedAddresses(emailData.ToList, suppressed)
+	emailData.Bcc = removeSuppressedAddresses(emailData.Bcc, suppressed)
+
+	var carbonCopy []string
+	for _, cc := range emailData.CarbonCopy {
+		if !suppressed[suppression.NormalizeAddress(cc)] {
+			carbonCopy = append(carbonCopy, cc)
+		}
+	}
+	emailData.CarbonCopy = carbonCopy
+	return emailData
+}
+
+func removeSuppressedAddresses(addresses []mail.Address, suppressed map[string]bool) []mail.Address {
+	var allowed []mail.Address
+	for _, address := range addresses {
+		if !suppressed[suppression.NormalizeAddress(address.Address)] {
+			allowed = append(allowed, address)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package emailsender
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"reflect"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+	"github.com/AccelByte/justice-go-common-email/object"
+	"github.com/AccelByte/justice-go-common-email/platform"
+	"github.com/AccelByte/justice-go-common-email/suppression"
+)
+
+// batchEmailSender records the emails and batches sent, each batch recipient is accepted.
+type batchEmailSender struct {
+	mockEmailSender
+	batches [][]object.Recipient
+}
+
+func (m *batchEmailSender) SendBatch(ctx context.Context, emailData object.EmailData, recipients []object.Recipient) ([]platform.BatchResult, error) {
+	m.batches = append(m.batches, recipients)
+	return []platform.BatchResult{{Recipients: object.GetRecipientAddresses(recipients)}}, nil
+}
+
+func newTestSuppressionStore(t *testing.T) suppression.Store {
+	store := suppression.NewMemoryStore()
+	for _, address := range []string{"bounced@example.com", "Complained@Example.com"} {
+		if err := store.Add(context.Background(), suppression.Entry{Namespace: "mygame", Address: address, Reason: events.TypeBouncedHard}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestSuppressionEmailSender_SendEmailWithResult(t *testing.T) {
+	testCases := []struct {
+		name             string
+		emailData        object.EmailData
+		expectedErr      error
+		expectedSent     *object.EmailData
+		expectedRejected []string
+		expectedAccepted []string
+	}{
+		{
+			name:             "no suppressed recipient",
+			emailData:        object.EmailData{Namespace: "mygame", To: "player@example.com"},
+			expectedSent:     &object.EmailData{Namespace: "mygame", To: "player@example.com"},
+			expectedAccepted: []string{"player@example.com"},
+		},
+		{
+			name: "suppressed recipients removed",
+			emailData: object.EmailData{
+				Namespace:  "mygame",
+				To:         "player@example.com",
+				ToList:     []mail.Address{{Address: "BOUNCED@example.com"}, {Address: "friend@example.com"}},
+				CarbonCopy: []string{"complained@example.com", "support@mygame.com"},
+				Bcc:        []mail.Address{{Address: "bounced@example.com"}},
+			},
+			expectedSent: &object.EmailData{
+				Namespace:  "mygame",
+				To:         "player@example.com",
+				ToList:     []mail.Address{{Address: "friend@example.com"}},
+				CarbonCopy: []string{"support@mygame.com"},
+			},
+			expectedRejected: []string{"BOUNCED@example.com", "complained@example.com", "bounced@example.com"},
+			expectedAccepted: []string{"player@example.com", "friend@example.com", "support@mygame.com"},
+		},
+		{
+			name: "To suppressed but ToList left",
+			emailData: object.EmailData{
+				Namespace: "mygame",
+				To:        "bounced@example.com",
+				ToList:    []mail.Address{{Address: "friend@example.com"}},
+			},
+			expectedSent: &object.EmailData{
+				Namespace: "mygame",
+				ToList:    []mail.Address{{Address: "friend@example.com"}},
+			},
+			expectedRejected: []string{"bounced@example.com"},
+			expectedAccepted: []string{"friend@example.com"},
+		},
+		{
+			name: "all To recipients suppressed",
+			emailData: object.EmailData{
+				Namespace:  "mygame",
+				To:         "bounced@example.com",
+				CarbonCopy: []string{"support@mygame.com"},
+			},
+			expectedErr: ErrRecipientSuppressed,
+		},
+		{
+			name:             "suppressed for another namespace",
+			emailData:        object.EmailData{Namespace: "othergame", To: "bounced@example.com"},
+			expectedSent:     &object.EmailData{Namespace: "othergame", To: "bounced@example.com"},
+			expectedAccepted: []string{"bounced@example.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			emailSender := &mockEmailSender{}
+			suppressionEmailSender := NewSuppressionEmailSender(emailSender, newTestSuppressionStore(t))
+			result, err := suppressionEmailSender.SendEmailWithResult(context.Background(), tc.emailData)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedSent == nil {
+				if len(emailSender.sent) != 0 {
+					t.Errorf("expected not sent, got %+v", emailSender.sent)
+				}
+				return
+			}
+			if len(emailSender.sent) != 1 || !reflect.DeepEqual(emailSender.sent[0], *tc.expectedSent) {
+				t.Errorf("expected sent %+v, got %+v", *tc.expectedSent, emailSender.sent)
+			}
+			if !reflect.DeepEqual(result.RejectedRecipients, tc.expectedRejected) {
+				t.Errorf("expected rejected %v, got %v", tc.expectedRejected, result.RejectedRecipients)
+			}
+			if !reflect.DeepEqual(result.AcceptedRecipients, tc.expectedAccepted) {
+				t.Errorf("expected accepted %v, got %v", tc.expectedAccepted, result.AcceptedRecipients)
+			}
+		})
+	}
+}
+
+func TestSuppressionEmailSender_SendBatch(t *testing.T) {
+	recipients := []object.Recipient{
+		{Address: mail.Address{Address: "player@example.com"}},
+		{Address: mail.Address{Address: "Bounced@example.com"}},
+		{Address: mail.Address{Address: "friend@example.com"}},
+	}
+	emailSender := &batchEmailSender{}
+	suppressionEmailSender := NewSuppressionEmailSender(emailSender, newTestSuppressionStore(t))
+
+	results, err := suppressionEmailSender.SendBatch(context.Background(), object.EmailData{Namespace: "mygame"}, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emailSender.batches) != 1 || !reflect.DeepEqual(object.GetRecipientAddresses(emailSender.batches[0]), []string{"player@example.com", "friend@example.com"}) {
+		t.Errorf("expected sent to the recipients not suppressed, got %v", emailSender.batches)
+	}
+	if len(results) != 2 || !errors.Is(results[1].Err, ErrRecipientSuppressed) || !reflect.DeepEqual(results[1].Recipients, []string{"Bounced@example.com"}) {
+		t.Errorf("expected the suppressed recipients reported separately, got %+v", results)
+	}
+
+	emailSender.batches = nil
+	results, err = suppressionEmailSender.SendBatch(context.Background(), object.EmailData{Namespace: "mygame"}, recipients[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emailSender.batches) != 0 || len(results) != 1 || !errors.Is(results[0].Err, ErrRecipientSuppressed) {
+		t.Errorf("expected nothing sent if all the recipients are suppressed, got %v %+v", emailSender.batches, results)
+	}
+}
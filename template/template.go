@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	SubjectTemplateSuffix = ".subject.tmpl"
+	HTMLTemplateSuffix    = ".html.tmpl"
+	TextTemplateSuffix    = ".txt.tmpl"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Content is the rendered email content.
+type Content struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Renderer renders email content locally, for platforms that don't store the templates on the provider side.
+type Renderer interface {
+	Render(ctx context.Context, name string, data map[string]interface{}) (*Content, error)
+}
+
+type templateSet struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// FSRenderer renders templates loaded from a fs.FS.
+//
+// A template named "verify" consists of the files "verify.subject.tmpl" (text/template),
+// "verify.html.tmpl" (html/template) and "verify.txt.tmpl" (text/template); each of them is optional
+// but at least one must exist. Templates in sub directories are named by their path, e.g. "account/verify".
+type FSRenderer struct {
+	templates map[string]*templateSet
+}
+
+// NewDirRenderer creates renderer loading templates from the given directory.
+func NewDirRenderer(dir string) (*FSRenderer, error) {
+	return NewFSRenderer(os.DirFS(dir))
+}
+
+// NewFSRenderer creates renderer loading templates from the given file system.
+// All templates are parsed up front, so syntax errors are reported here instead of on send.
+func NewFSRenderer(fsys fs.FS) (*FSRenderer, error) {
+	renderer := &FSRenderer{templates: map[string]*templateSet{}}
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		var name string
+		var suffix string
+		for _, s := range []string{SubjectTemplateSuffix, HTMLTemplateSuffix, TextTemplateSuffix} {
+			if strings.HasSuffix(filePath, s) {
+				name, suffix = strings.TrimSuffix(filePath, s), s
+				break
+			}
+		}
+		if name == "" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		set, ok := renderer.templates[name]
+		if !ok {
+			set = &templateSet{}
+			renderer.templates[name] = set
+		}
+		fileName := path.Base(filePath)
+		switch suffix {
+		case SubjectTemplateSuffix:
+			set.subject, err = texttemplate.New(fileName).Option("missingkey=error").Parse(string(content))
+		case HTMLTemplateSuffix:
+			set.html, err = htmltemplate.New(fileName).Option("missingkey=error").Parse(string(content))
+		case TextTemplateSuffix:
+			set.text, err = texttemplate.New(fileName).Option("missingkey=error").Parse(string(content))
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse template %s: %v", filePath, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return renderer, nil
+}
+
+func (r *FSRenderer) Render(_ context.Context, name string, data map[string]interface{}) (*Content, error) {
+	set, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	content := &Content{}
+	buf := &bytes.Buffer{}
+	if set.subject != nil {
+		if err := set.subject.Execute(buf, data); err != nil {
+			return nil, err
+		}
+		content.Subject = strings.TrimSpace(buf.String())
+		buf.Reset()
+	}
+	if set.html != nil {
+		if err := set.html.Execute(buf, data); err != nil {
+			return nil, err
+		}
+		content.HTML = buf.String()
+		buf.Reset()
+	}
+	if set.text != nil {
+		if err := set.text.Execute(buf, data); err != nil {
+			return nil, err
+		}
+		content.Text = buf.String()
+	}
+	return content, nil
+}
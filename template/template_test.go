@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestRenderer(t *testing.T) *FSRenderer {
+	renderer, err := NewFSRenderer(fstest.MapFS{
+		"verify.subject.tmpl":         {Data: []byte("  Verify your {{.game}} account\n")},
+		"verify.html.tmpl":            {Data: []byte("<p>Hi {{.name}}, your code is {{.code}}</p>")},
+		"verify.txt.tmpl":             {Data: []byte("Hi {{.name}}, your code is {{.code}}")},
+		"account/reset.txt.tmpl":      {Data: []byte("Reset code: {{.code}}")},
+		"welcome.html.tmpl":           {Data: []byte("<p>Welcome {{.name}}</p>")},
+		"README.md":                   {Data: []byte("not a template")},
+		"newsletter.subject.tmpl.bak": {Data: []byte("{{.ignored")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return renderer
+}
+
+func TestFSRenderer_Render(t *testing.T) {
+	renderer := newTestRenderer(t)
+	testCases := []struct {
+		name            string
+		template        string
+		data            map[string]interface{}
+		expectedContent Content
+		expectedErr     error
+		expectErr       bool
+	}{
+		{
+			name:     "subject, html and text",
+			template: "verify",
+			data:     map[string]interface{}{"game": "My Game", "name": "Player", "code": 123456},
+			expectedContent: Content{
+				Subject: "Verify your My Game account",
+				HTML:    "<p>Hi Player, your code is 123456</p>",
+				Text:    "Hi Player, your code is 123456",
+			},
+		},
+		{
+			name:     "html is escaped, text is not",
+			template: "verify",
+			data:     map[string]interface{}{"game": "<b>Game</b>", "name": "<script>", "code": "a&b"},
+			expectedContent: Content{
+				Subject: "Verify your <b>Game</b> account",
+				HTML:    "<p>Hi &lt;script&gt;, your code is a&amp;b</p>",
+				Text:    "Hi <script>, your code is a&b",
+			},
+		},
+		{
+			name:            "template in sub directory",
+			template:        "account/reset",
+			data:            map[string]interface{}{"code": "123456"},
+			expectedContent: Content{Text: "Reset code: 123456"},
+		},
+		{
+			name:            "html only",
+			template:        "welcome",
+			data:            map[string]interface{}{"name": "Player"},
+			expectedContent: Content{HTML: "<p>Welcome Player</p>"},
+		},
+		{
+			name:      "missing key in subject",
+			template:  "verify",
+			data:      map[string]interface{}{"name": "Player", "code": 123456},
+			expectErr: true,
+		},
+		{
+			name:      "missing key in html",
+			template:  "welcome",
+			data:      map[string]interface{}{},
+			expectErr: true,
+		},
+		{
+			name:      "missing key in text",
+			template:  "account/reset",
+			expectErr: true,
+		},
+		{
+			name:        "template not found",
+			template:    "newsletter",
+			expectedErr: ErrTemplateNotFound,
+			expectErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			content, err := renderer.Render(context.Background(), testCase.template, testCase.data)
+			if testCase.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", content)
+				}
+				if testCase.expectedErr != nil && !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("expected %v, got %v", testCase.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *content != testCase.expectedContent {
+				t.Errorf("expected %+v, got %+v", testCase.expectedContent, *content)
+			}
+		})
+	}
+}
+
+func TestNewFSRenderer_ParseError(t *testing.T) {
+	_, err := NewFSRenderer(fstest.MapFS{
+		"verify.html.tmpl": {Data: []byte("<p>{{.code</p>")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "verify.html.tmpl") {
+		t.Errorf("expected parse error of verify.html.tmpl, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mandrill
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint: gosec
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/constant"
+	"github.com/AccelByte/justice-go-common-email/events"
+	mandrillplatform "github.com/AccelByte/justice-go-common-email/platform/mandrill"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SignatureHeader = "X-Mandrill-Signature"
+	EventsFormField = "mandrill_events"
+
+	maxBodySize = 10 << 20
+)
+
+var ErrInvalidSignature = errors.New("mandrill webhook signature is not valid")
+
+type message struct {
+	ID                string                 `json:"_id"`
+	Email             string                 `json:"email"`
+	BounceDescription string                 `json:"bounce_description"`
+	Diag              string                 `json:"diag"`
+	Metadata          map[string]interface{} `json:"metadata"`
+}
+
+type event struct {
+	Event     string  `json:"event"`
+	Timestamp int64   `json:"ts"`
+	ID        string  `json:"_id"`
+	URL       string  `json:"url"`
+	Msg       message `json:"msg"`
+}
+
+// Handler receives the Mandrill webhook, and passes the normalized delivery events to EventHandler.
+type Handler struct {
+	// WebhookKey is the key of the webhook, shown in the Mandrill webhook settings.
+	WebhookKey string
+	// URL is the webhook URL exactly as registered in Mandrill, it's signed along with the events.
+	URL          string
+	EventHandler events.Handler
+}
+
+func NewHandler(webhookKey, webhookURL string, eventHandler events.Handler) *Handler {
+	return &Handler{
+		WebhookKey:   webhookKey,
+		URL:          webhookURL,
+		EventHandler: eventHandler,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Mandrill checks the webhook URL exists with a HEAD request when the webhook is added
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := h.Verify(r.Header.Get(SignatureHeader), r.PostForm); err != nil {
+		logrus.Warnf("fail verify mandrill webhook. error: %v", err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	deliveryEvents, err := ParseEvents([]byte(r.PostForm.Get(EventsFormField)))
+	if err != nil {
+		logrus.Errorf("fail parse mandrill webhook. error: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, deliveryEvent := range deliveryEvents {
+		if err = h.EventHandler.HandleDeliveryEvent(r.Context(), deliveryEvent); err != nil {
+			logrus.Errorf("fail handle mandrill %s event of %s. error: %v", deliveryEvent.Type, deliveryEvent.Recipient, err)
+			// Mandrill retries the whole batch of events
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verify verifies the base64 encoded HMAC-SHA1 signature, keyed by the webhook key,
+// over the webhook URL followed by each POST parameter key and value sorted by key.
+func (h *Handler) Verify(signature string, params url.Values) error {
+	if signature == "" {
+		return ErrInvalidSignature
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var signedData strings.Builder
+	signedData.WriteString(h.URL)
+	for _, key := range keys {
+		for _, value := range params[key] {
+			signedData.WriteString(key)
+			signedData.WriteString(value)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(h.WebhookKey))
+	_, _ = mac.Write([]byte(signedData.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseEvents parses the mandrill_events form field into the delivery events,
+// skipping the events not normalized, e.g. reject or the sync events.
+func ParseEvents(payload []byte) ([]events.DeliveryEvent, error) {
+	var rawEvents []json.RawMessage
+	if err := json.Unmarshal(payload, &rawEvents); err != nil {
+		return nil, err
+	}
+	deliveryEvents := make([]events.DeliveryEvent, 0, len(rawEvents))
+	for _, rawEvent := range rawEvents {
+		e := event{}
+		if err := json.Unmarshal(rawEvent, &e); err != nil {
+			return nil, err
+		}
+		eventType, ok := getEventType(e.Event)
+		if !ok {
+			continue
+		}
+		messageID := e.Msg.ID
+		if messageID == "" {
+			messageID = e.ID
+		}
+		reason := e.Msg.BounceDescription
+		if e.Msg.Diag != "" {
+			reason = strings.TrimSpace(reason + " " + e.Msg.Diag)
+		}
+		deliveryEvents = append(deliveryEvents, events.DeliveryEvent{
+			Type:      eventType,
+			Provider:  mandrillplatform.PlatformID,
+			Namespace: getMetadataValue(e.Msg.Metadata, constant.NamespaceMetadataKey),
+			Recipient: e.Msg.Email,
+			MessageID: messageID,
+			Timestamp: time.Unix(e.Timestamp, 0),
+			Reason:    reason,
+			URL:       e.URL,
+			Raw:       rawEvent,
+		})
+	}
+	return deliveryEvents, nil
+}
+
+func getEventType(eventName string) (events.Type, bool) {
+	switch eventName {
+	// Mandrill doesn't report the delivery, send is the closest, i.e. the email is sent to the recipient server
+	case "send":
+		return events.TypeDelivered, true
+	case "hard_bounce":
+		return events.TypeBouncedHard, true
+	case "soft_bounce":
+		return events.TypeBouncedSoft, true
+	case "deferral":
+		return events.TypeDeferred, true
+	case "open":
+		return events.TypeOpened, true
+	case "click":
+		return events.TypeClicked, true
+	case "spam":
+		return events.TypeComplained, true
+	case "unsub":
+		return events.TypeUnsubscribed, true
+	default:
+		return "", false
+	}
+}
+
+// getMetadataValue returns the metadata value as string, the metadata set outside this library could hold any JSON value.
+func getMetadataValue(metadata map[string]interface{}, key string) string {
+	switch value := metadata[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package mandrill
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+// the signatures are computed as documented by Mandrill: base64(HMAC-SHA1(key, URL + sorted key/value pairs))
+const (
+	testWebhookKey = "fUjD6j9cjIUrcuFp1bb7Wg"
+	testWebhookURL = "https://mygame.com/webhooks/mandrill"
+	testEvents     = `[{"event":"hard_bounce","ts":1700000000,"_id":"msg-id","msg":{"_id":"msg-id","email":"player@example.com",` +
+		`"bounce_description":"bad_mailbox","diag":"smtp;550 5.1.1 unknown user","metadata":{"namespace":"mygame"}}}]`
+	testEventsSignature = "hDpJpt/PhTiMpftnathrrPFT5EI="
+	// signature of a_param=1, b_param=2 and mandrill_events=[], checking the params are sorted by key
+	testSortedParamsSignature = "1Vif2BhBkePVsrLmQDPeBmzbHNk="
+)
+
+func TestHandler_Verify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		signature string
+		params    url.Values
+		valid     bool
+	}{
+		{name: "valid", key: testWebhookKey, signature: testEventsSignature,
+			params: url.Values{EventsFormField: {testEvents}}, valid: true},
+		{name: "valid sorted params", key: testWebhookKey, signature: testSortedParamsSignature,
+			params: url.Values{"b_param": {"2"}, EventsFormField: {"[]"}, "a_param": {"1"}}, valid: true},
+		{name: "tampered param", key: testWebhookKey, signature: testEventsSignature,
+			params: url.Values{EventsFormField: {strings.Replace(testEvents, "hard_bounce", "send", 1)}}},
+		{name: "added param", key: testWebhookKey, signature: testEventsSignature,
+			params: url.Values{EventsFormField: {testEvents}, "extra": {"1"}}},
+		{name: "wrong key", key: "another-key", signature: testEventsSignature,
+			params: url.Values{EventsFormField: {testEvents}}},
+		{name: "missing signature", key: testWebhookKey, params: url.Values{EventsFormField: {testEvents}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(tc.key, testWebhookURL, nil)
+			err := handler.Verify(tc.signature, tc.params)
+			if tc.valid && err != nil {
+				t.Errorf("expected valid signature, got %v", err)
+			}
+			if !tc.valid && err != ErrInvalidSignature {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	var received []events.DeliveryEvent
+	handler := NewHandler(testWebhookKey, testWebhookURL, events.HandlerFunc(func(_ context.Context, event events.DeliveryEvent) error {
+		received = append(received, event)
+		return nil
+	}))
+
+	form := url.Values{EventsFormField: {testEvents}}
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/mandrill", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(SignatureHeader, testEventsSignature)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(received))
+	}
+	event := received[0]
+	if event.Type != events.TypeBouncedHard || event.Recipient != "player@example.com" ||
+		event.MessageID != "msg-id" || event.Namespace != "mygame" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestParseEvents_NonStringMetadata(t *testing.T) {
+	payload := `[{"event":"open","ts":1700000000,"msg":{"_id":"id-1","email":"a@example.com","metadata":{"namespace":12345,"user_id":true}}},` +
+		`{"event":"spam","ts":1700000000,"msg":{"_id":"id-2","email":"b@example.com","metadata":null}}]`
+	deliveryEvents, err := ParseEvents([]byte(payload))
+	if err != nil {
+		t.Fatalf("expected the events to parse, got %v", err)
+	}
+	if len(deliveryEvents) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(deliveryEvents))
+	}
+	if deliveryEvents[0].Namespace != "12345" {
+		t.Errorf("expected namespace 12345, got %q", deliveryEvents[0].Namespace)
+	}
+	if deliveryEvents[1].Namespace != "" {
+		t.Errorf("expected empty namespace, got %q", deliveryEvents[1].Namespace)
+	}
+}
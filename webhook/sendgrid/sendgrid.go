@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package sendgrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+	sendgridplatform "github.com/AccelByte/justice-go-common-email/platform/sendgrid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	TimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+
+	// DefaultMaxTimestampAge is how far the signed timestamp could be from now by default.
+	DefaultMaxTimestampAge = 10 * time.Minute
+
+	maxBodySize = 10 << 20
+)
+
+var ErrInvalidSignature = errors.New("sendgrid event webhook signature is not valid")
+
+type event struct {
+	Email       string `json:"email"`
+	Timestamp   int64  `json:"timestamp"`
+	Event       string `json:"event"`
+	SGMessageID string `json:"sg_message_id"`
+	Reason      string `json:"reason"`
+	Response    string `json:"response"`
+	Type        string `json:"type"`
+	URL         string `json:"url"`
+	// Namespace is the custom arg set on the sent email, SendGrid reports the custom args along with the event.
+	Namespace string `json:"namespace"`
+}
+
+// Handler receives the SendGrid signed Event Webhook, and passes the normalized delivery events to EventHandler.
+type Handler struct {
+	PublicKey    *ecdsa.PublicKey
+	EventHandler events.Handler
+	// MaxTimestampAge rejects the requests signed longer ago, or dated further in the future, to prevent replay.
+	// Zero disables the check.
+	MaxTimestampAge time.Duration
+}
+
+// NewHandler creates the webhook handler verifying the requests with the verification key
+// of the SendGrid Event Webhook settings, i.e. the base64 encoded ECDSA public key.
+func NewHandler(publicKey string, eventHandler events.Handler) (*Handler, error) {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		PublicKey:       key,
+		EventHandler:    eventHandler,
+		MaxTimestampAge: DefaultMaxTimestampAge,
+	}, nil
+}
+
+// ParsePublicKey parses the base64 encoded ECDSA public key of the SendGrid Event Webhook settings.
+func ParsePublicKey(publicKey string) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail decode sendgrid event webhook public key: %w", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("fail parse sendgrid event webhook public key: %w", err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sendgrid event webhook public key is not an ECDSA key")
+	}
+	return ecdsaKey, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err = h.Verify(r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body); err != nil {
+		logrus.Warnf("fail verify sendgrid event webhook. error: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	deliveryEvents, err := ParseEvents(body)
+	if err != nil {
+		logrus.Errorf("fail parse sendgrid event webhook. error: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, deliveryEvent := range deliveryEvents {
+		if err = h.EventHandler.HandleDeliveryEvent(r.Context(), deliveryEvent); err != nil {
+			logrus.Errorf("fail handle sendgrid %s event of %s. error: %v", deliveryEvent.Type, deliveryEvent.Recipient, err)
+			// SendGrid retries the whole batch of events
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verify verifies the ECDSA signature over the timestamp followed by the payload.
+func (h *Handler) Verify(signature, timestamp string, payload []byte) error {
+	if signature == "" || timestamp == "" {
+		return ErrInvalidSignature
+	}
+	if h.MaxTimestampAge > 0 {
+		unixTimestamp, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		age := time.Since(time.Unix(unixTimestamp, 0))
+		if age > h.MaxTimestampAge || age < -h.MaxTimestampAge {
+			return ErrInvalidSignature
+		}
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(timestamp))
+	_, _ = hash.Write(payload)
+	if !ecdsa.VerifyASN1(h.PublicKey, hash.Sum(nil), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseEvents parses the Event Webhook payload into the delivery events, skipping the events not normalized, e.g. processed.
+func ParseEvents(payload []byte) ([]events.DeliveryEvent, error) {
+	var rawEvents []json.RawMessage
+	if err := json.Unmarshal(payload, &rawEvents); err != nil {
+		return nil, err
+	}
+	deliveryEvents := make([]events.DeliveryEvent, 0, len(rawEvents))
+	for _, rawEvent := range rawEvents {
+		e := event{}
+		if err := json.Unmarshal(rawEvent, &e); err != nil {
+			return nil, err
+		}
+		eventType, ok := getEventType(e)
+		if !ok {
+			continue
+		}
+		reason := e.Reason
+		if reason == "" {
+			reason = e.Response
+		}
+		deliveryEvents = append(deliveryEvents, events.DeliveryEvent{
+			Type:      eventType,
+			Provider:  sendgridplatform.PlatformID,
+			Namespace: e.Namespace,
+			Recipient: e.Email,
+			MessageID: getMessageID(e.SGMessageID),
+			Timestamp: time.Unix(e.Timestamp, 0),
+			Reason:    reason,
+			URL:       e.URL,
+			Raw:       rawEvent,
+		})
+	}
+	return deliveryEvents, nil
+}
+
+func getEventType(e event) (events.Type, bool) {
+	switch e.Event {
+	case "delivered":
+		return events.TypeDelivered, true
+	case "bounce":
+		// blocked bounces are temporary, e.g. the recipient server rejected the sender IP
+		if e.Type == "blocked" {
+			return events.TypeBouncedSoft, true
+		}
+		return events.TypeBouncedHard, true
+	case "dropped":
+		return getDroppedEventType(e.Reason), true
+	case "deferred":
+		return events.TypeDeferred, true
+	case "open":
+		return events.TypeOpened, true
+	case "click":
+		return events.TypeClicked, true
+	case "spamreport":
+		return events.TypeComplained, true
+	case "unsubscribe", "group_unsubscribe":
+		return events.TypeUnsubscribed, true
+	default:
+		return "", false
+	}
+}
+
+// getDroppedEventType maps the reason SendGrid dropped the email without sending it,
+// e.g. the recipient is on the SendGrid suppression lists.
+func getDroppedEventType(reason string) events.Type {
+	switch strings.ToLower(reason) {
+	case "bounced address", "invalid":
+		return events.TypeBouncedHard
+	case "spam reporting address":
+		return events.TypeComplained
+	case "unsubscribed address":
+		return events.TypeUnsubscribed
+	default:
+		return events.TypeBouncedSoft
+	}
+}
+
+// getMessageID returns the X-Message-Id of the sent email, sg_message_id is X-Message-Id followed by the filter suffix.
+func getMessageID(sgMessageID string) string {
+	if i := strings.Index(sgMessageID, "."); i >= 0 {
+		return sgMessageID[:i]
+	}
+	return sgMessageID
+}
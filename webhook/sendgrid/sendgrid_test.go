@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2023 AccelByte Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and limitations under the License.
+ *
+ */
+
+package sendgrid
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/justice-go-common-email/events"
+)
+
+const testPayload = `[{"email":"player@example.com","timestamp":1700000000,"event":"bounce","type":"bounce",` +
+	`"sg_message_id":"msg-id.filter0001","reason":"550 5.1.1 unknown user","namespace":"mygame"}]`
+
+func newTestHandler(t *testing.T, eventHandler events.Handler) (*Handler, *ecdsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("fail generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("fail marshal public key: %v", err)
+	}
+	handler, err := NewHandler(base64.StdEncoding.EncodeToString(der), eventHandler)
+	if err != nil {
+		t.Fatalf("fail create handler: %v", err)
+	}
+	return handler, privateKey
+}
+
+func sign(t *testing.T, privateKey *ecdsa.PrivateKey, timestamp string, payload string) string {
+	t.Helper()
+	hash := sha256.Sum256([]byte(timestamp + payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, privateKey, hash[:])
+	if err != nil {
+		t.Fatalf("fail sign: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestHandler_Verify(t *testing.T) {
+	handler, privateKey := newTestHandler(t, nil)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	testCases := []struct {
+		name      string
+		signature string
+		timestamp string
+		payload   string
+		valid     bool
+	}{
+		{name: "valid", signature: sign(t, privateKey, now, testPayload), timestamp: now, payload: testPayload, valid: true},
+		{name: "tampered body", signature: sign(t, privateKey, now, testPayload), timestamp: now,
+			payload: strings.Replace(testPayload, "bounce", "delivered", 1)},
+		{name: "tampered timestamp", signature: sign(t, privateKey, now, testPayload),
+			timestamp: strconv.FormatInt(time.Now().Unix()-1, 10), payload: testPayload},
+		{name: "stale timestamp", signature: sign(t, privateKey, stale, testPayload), timestamp: stale, payload: testPayload},
+		{name: "future timestamp", signature: sign(t, privateKey, future, testPayload), timestamp: future, payload: testPayload},
+		{name: "missing signature", timestamp: now, payload: testPayload},
+		{name: "malformed signature", signature: "not base64!", timestamp: now, payload: testPayload},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := handler.Verify(tc.signature, tc.timestamp, []byte(tc.payload))
+			if tc.valid && err != nil {
+				t.Errorf("expected valid signature, got %v", err)
+			}
+			if !tc.valid && err != ErrInvalidSignature {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	var received []events.DeliveryEvent
+	handler, privateKey := newTestHandler(t, events.HandlerFunc(func(_ context.Context, event events.DeliveryEvent) error {
+		received = append(received, event)
+		return nil
+	}))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/sendgrid", strings.NewReader(testPayload))
+	req.Header.Set(SignatureHeader, sign(t, privateKey, timestamp, testPayload))
+	req.Header.Set(TimestampHeader, timestamp)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(received))
+	}
+	event := received[0]
+	if event.Type != events.TypeBouncedHard || event.Recipient != "player@example.com" ||
+		event.MessageID != "msg-id" || event.Namespace != "mygame" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/sendgrid", strings.NewReader(testPayload))
+	req.Header.Set(SignatureHeader, sign(t, privateKey, timestamp, "[]"))
+	req.Header.Set(TimestampHeader, timestamp)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for the invalid signature, got %d", recorder.Code)
+	}
+}